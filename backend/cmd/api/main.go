@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/auth"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/database"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/handler"
@@ -23,7 +24,7 @@ func main() {
 
 	// Inicializar DB
 	ctx := context.Background()
-	dbPool, err := database.NewPostgresConnection(ctx, cfg.DBUrl)
+	dbPool, err := database.NewPostgresConnection(ctx, cfg.DBUrl, cfg.DBStatementTimeout)
 	if err != nil {
 		log.Fatalf("Error fatal conectando a la base de datos: %v", err)
 	}
@@ -31,8 +32,20 @@ func main() {
 	log.Println("Conectado a PostgreSQL exitosamente")
 
 	repo := repository.NewSpotifyRepository(dbPool)
-	svc := service.NewSpotifyService(repo)
-	router := handler.NewRouter(svc)
+	users := repository.NewUserRepository(dbPool)
+	svc := service.NewSpotifyService(repo, users)
+	presetSvc := service.NewPresetService(repository.NewPresetRepository(dbPool))
+	exclusionSvc := service.NewExclusionService(repository.NewExclusionRepository(dbPool))
+	shareSvc := service.NewShareService(repository.NewShareRepository(dbPool))
+
+	authn, err := auth.New(cfg.Auth, users)
+	if err != nil {
+		log.Fatalf("Error fatal en la configuración de autenticación: %v", err)
+	}
+	if authn == nil {
+		log.Println("Aviso: autenticación deshabilitada (sin API_KEYS ni JWT), todas las peticiones usan el usuario por defecto")
+	}
+	router := handler.NewRouter(svc, presetSvc, exclusionSvc, shareSvc, authn, cfg.CORS)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/database"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/importer"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// Uso: go run ./cmd/import -user isaac Streaming_History_Audio_2023.json Streaming_History_Audio_2024.json
+// Sin -user los registros quedan en el usuario por defecto. El usuario debe existir: -create-user lo crea,
+// así un error de tipeo en -user no termina en un usuario nuevo con el historial
+func main() {
+	username := flag.String("user", domain.DefaultUsername, "usuario dueño del historial")
+	createUser := flag.Bool("create-user", false, "crea el usuario si no existe")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("Uso: import [-user <usuario>] [-create-user] <archivo.json> [archivo.json...]")
+	}
+
+	cfg := config.Load()
+
+	ctx := context.Background()
+	dbPool, err := database.NewPostgresConnection(ctx, cfg.DBUrl, 0) // Importar un export grande puede tardar minutos
+	if err != nil {
+		log.Fatalf("Error fatal conectando a la base de datos: %v", err)
+	}
+	defer dbPool.Close()
+
+	repo := repository.NewImportRepository(dbPool)
+
+	users := repository.NewUserRepository(dbPool)
+	var user domain.User
+	if *createUser {
+		user, err = users.Ensure(ctx, *username)
+	} else {
+		user, err = users.GetByUsername(ctx, *username)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Fatalf("El usuario %q no existe (usa -create-user para crearlo)", *username)
+	}
+	if err != nil {
+		log.Fatalf("Error fatal: %v", err)
+	}
+
+	var total int64
+	for _, path := range flag.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Error abriendo %s: %v", path, err)
+		}
+		records, err := importer.ParseStreamingHistory(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("Error procesando %s: %v", path, err)
+		}
+
+		n, err := repo.InsertRecords(ctx, user.ID, records)
+		if err != nil {
+			log.Fatalf("Error insertando %s: %v", path, err)
+		}
+		log.Printf("%s: %d registros importados, %d ya existían", path, n, int64(len(records))-n)
+		total += n
+	}
+
+	log.Printf("Importación finalizada: %d registros para el usuario %s (id %d)", total, user.Username, user.ID)
+}
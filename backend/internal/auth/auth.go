@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// ErrNoCredentials indica que la petición no trae API key ni token
+var ErrNoCredentials = errors.New("sin credenciales")
+
+// UserLookup resuelve el usuario de una credencial (repository.UserRepository lo cumple)
+type UserLookup interface {
+	GetByUsername(ctx context.Context, username string) (domain.User, error)
+}
+
+// principal es lo que acredita una credencial antes de resolver el usuario en la base de datos
+type principal struct {
+	username string
+	role     domain.Role
+	keyHash  *[sha256.Size]byte // Solo con API key
+	expires  time.Time          // Solo con JWT
+}
+
+// Authenticator valida API keys estáticas (header X-API-Key) y JWT HS256/RS256 (Authorization: Bearer)
+type Authenticator struct {
+	keys       map[[sha256.Size]byte]principal // Indexadas por hash para no comparar la llave en texto plano
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	issuer     string
+	audience   string
+	users      UserLookup
+	now        func() time.Time
+
+	sessionKey    []byte // Firma las cookies del dashboard
+	secureCookies bool
+}
+
+// New construye el autenticador. Retorna nil si la configuración no define ninguna credencial
+func New(cfg config.AuthConfig, users UserLookup) (*Authenticator, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	a := &Authenticator{
+		keys:     map[[sha256.Size]byte]principal{},
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+		users:    users,
+		now:      time.Now,
+
+		sessionKey:    []byte(cfg.SessionSecret),
+		secureCookies: cfg.SecureCookies,
+	}
+	// Sin SESSION_SECRET se usa una llave aleatoria: las sesiones no sobreviven a un reinicio
+	if len(a.sessionKey) == 0 {
+		a.sessionKey = make([]byte, 32)
+		if _, err := rand.Read(a.sessionKey); err != nil {
+			return nil, fmt.Errorf("no se pudo generar la llave de sesión: %v", err)
+		}
+	}
+	for _, k := range cfg.APIKeys {
+		role := domain.Role(k.Role)
+		if !role.IsValid() {
+			return nil, fmt.Errorf("rol %q inválido para la API key de %s, use reader o admin", k.Role, k.Username)
+		}
+		a.keys[sha256.Sum256([]byte(k.Key))] = principal{username: k.Username, role: role}
+	}
+	if cfg.JWTSecret != "" {
+		a.hmacSecret = []byte(cfg.JWTSecret)
+	}
+	if len(cfg.JWTPublicKeyPEM) > 0 {
+		key, err := parseRSAPublicKey(cfg.JWTPublicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		a.rsaKey = key
+	}
+	return a, nil
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("la llave pública RS256 no está en formato PEM")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la llave pública RS256: %v", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("la llave pública de JWT_RS256_PUBLIC_KEY_FILE no es RSA")
+	}
+	return key, nil
+}
+
+// Authenticate identifica al autor de la petición. Retorna ErrNoCredentials si no trae
+// credenciales, un error 401 si son inválidas o un error interno si falla la base de datos
+func (a *Authenticator) Authenticate(r *http.Request) (domain.Identity, error) {
+	var p principal
+	var err error
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		p, err = a.apiKey(key)
+	} else if authz := r.Header.Get("Authorization"); authz != "" {
+		scheme, token, _ := strings.Cut(authz, " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return domain.Identity{}, domain.NewUnauthorizedError("El header Authorization debe tener la forma Bearer <token>")
+		}
+		p, err = a.bearer(strings.TrimSpace(token))
+	} else {
+		return domain.Identity{}, ErrNoCredentials
+	}
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	return a.identity(r.Context(), p)
+}
+
+// credential valida una API key o un JWT recibido fuera de los headers (el formulario del
+// dashboard). Un valor con la forma de un JWT (tres segmentos) se verifica como token
+func (a *Authenticator) credential(credential string) (principal, error) {
+	credential = strings.TrimSpace(credential)
+	if credential == "" {
+		return principal{}, ErrNoCredentials
+	}
+	if strings.Count(credential, ".") == 2 {
+		return a.bearer(credential)
+	}
+	return a.apiKey(credential)
+}
+
+func (a *Authenticator) apiKey(key string) (principal, error) {
+	hash := sha256.Sum256([]byte(key))
+	p, ok := a.keys[hash]
+	if !ok {
+		return p, domain.NewUnauthorizedError("API key inválida")
+	}
+	p.keyHash = &hash
+	return p, nil
+}
+
+func (a *Authenticator) bearer(token string) (principal, error) {
+	claims, err := a.verifyJWT(token)
+	if err != nil {
+		return principal{}, domain.NewUnauthorizedError("Token inválido: %v", err)
+	}
+	return principal{username: claims.Subject, role: claims.Role, expires: time.Unix(int64(*claims.ExpiresAt), 0)}, nil
+}
+
+// identity resuelve el usuario de la credencial ya validada
+func (a *Authenticator) identity(ctx context.Context, p principal) (domain.Identity, error) {
+	user, err := a.users.GetByUsername(ctx, p.username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.Identity{}, domain.NewUnauthorizedError("El usuario %q no existe", p.username)
+	}
+	if err != nil {
+		return domain.Identity{}, domain.NewInternalError(err, "Error al verificar credenciales")
+	}
+	return domain.Identity{UserID: user.ID, Username: user.Username, Role: p.role}, nil
+}
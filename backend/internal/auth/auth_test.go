@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// usersStub resuelve usernames desde un mapa; err simula una falla de la base de datos
+type usersStub struct {
+	users map[string]int
+	err   error
+}
+
+func (u usersStub) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	if u.err != nil {
+		return domain.User{}, u.err
+	}
+	id, ok := u.users[username]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	return domain.User{ID: id, Username: username}, nil
+}
+
+func TestNew(t *testing.T) {
+	a, err := New(config.AuthConfig{}, nil)
+	if a != nil || err != nil {
+		t.Errorf("sin credenciales configuradas: %v, %v; se esperaba autenticación deshabilitada", a, err)
+	}
+
+	invalid := []config.AuthConfig{
+		{APIKeys: []config.APIKey{{Key: "k", Username: "ana", Role: "owner"}}},
+		{APIKeys: []config.APIKey{{Key: "k", Username: "ana", Role: ""}}},
+		{JWTPublicKeyPEM: []byte("no es PEM")},
+	}
+	for _, cfg := range invalid {
+		if _, err := New(cfg, nil); err == nil {
+			t.Errorf("New(%+v) no falló", cfg)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	cfg := config.AuthConfig{
+		APIKeys: []config.APIKey{
+			{Key: "llave-lectura", Username: "ana", Role: "reader"},
+			{Key: "llave-admin", Username: "root", Role: "admin"},
+			{Key: "llave-huerfana", Username: "nadie", Role: "reader"},
+		},
+		JWTSecret: testSecret,
+	}
+	users := usersStub{users: map[string]int{"ana": 2, "root": 3}}
+	token := func(sub string, role interface{}) string {
+		return signHS256(hs256, claims(map[string]interface{}{"sub": sub, "role": role}), []byte(testSecret))
+	}
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		want     domain.Identity
+		wantKind domain.ErrorKind // Vacío si se espera éxito
+		noCreds  bool
+	}{
+		{name: "API key reader", headers: map[string]string{"X-API-Key": "llave-lectura"}, want: domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}},
+		{name: "API key admin", headers: map[string]string{"X-API-Key": "llave-admin"}, want: domain.Identity{UserID: 3, Username: "root", Role: domain.RoleAdmin}},
+		{name: "API key desconocida", headers: map[string]string{"X-API-Key": "llave-lectura "}, wantKind: domain.ErrKindUnauthorized},
+		{name: "API key de un usuario inexistente", headers: map[string]string{"X-API-Key": "llave-huerfana"}, wantKind: domain.ErrKindUnauthorized},
+		{name: "API key tiene prioridad sobre Bearer", headers: map[string]string{"X-API-Key": "llave-lectura", "Authorization": "Bearer " + token("root", "admin")}, want: domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}},
+		{name: "Bearer válido", headers: map[string]string{"Authorization": "Bearer " + token("root", "admin")}, want: domain.Identity{UserID: 3, Username: "root", Role: domain.RoleAdmin}},
+		{name: "esquema bearer en minúsculas", headers: map[string]string{"Authorization": "bearer " + token("ana", nil)}, want: domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}},
+		{name: "Basic no es válido", headers: map[string]string{"Authorization": "Basic YW5hOjEyMw=="}, wantKind: domain.ErrKindUnauthorized},
+		{name: "Bearer sin token", headers: map[string]string{"Authorization": "Bearer"}, wantKind: domain.ErrKindUnauthorized},
+		{name: "token falsificado", headers: map[string]string{"Authorization": "Bearer " + signHS256(hs256, claims(nil), []byte("otro"))}, wantKind: domain.ErrKindUnauthorized},
+		{name: "token de un usuario inexistente", headers: map[string]string{"Authorization": "Bearer " + token("eva", nil)}, wantKind: domain.ErrKindUnauthorized},
+		{name: "sin credenciales", noCreds: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, cfg)
+			a.users = users
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			id, err := a.Authenticate(r)
+			switch {
+			case tt.noCreds:
+				if !errors.Is(err, ErrNoCredentials) {
+					t.Fatalf("error %v, se esperaba ErrNoCredentials", err)
+				}
+			case tt.wantKind != "":
+				var appErr *domain.AppError
+				if !errors.As(err, &appErr) || appErr.Kind != tt.wantKind {
+					t.Fatalf("error %v, se esperaba un error %s", err, tt.wantKind)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id != tt.want {
+					t.Errorf("identidad %+v, se esperaba %+v", id, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticateDatabaseError(t *testing.T) {
+	a := newTestAuthenticator(t, config.AuthConfig{APIKeys: []config.APIKey{{Key: "k", Username: "ana", Role: "reader"}}})
+	a.users = usersStub{err: errors.New("conexión rechazada")}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-API-Key", "k")
+
+	// Una falla de la base de datos no debe responderse como credencial inválida
+	var appErr *domain.AppError
+	if _, err := a.Authenticate(r); !errors.As(err, &appErr) || appErr.Kind != domain.ErrKindInternal {
+		t.Fatalf("error %v, se esperaba un error interno", err)
+	}
+}
+
+func TestNewSession(t *testing.T) {
+	a := newTestAuthenticator(t, config.AuthConfig{
+		APIKeys:   []config.APIKey{{Key: "llave-lectura", Username: "ana", Role: "reader"}},
+		JWTSecret: testSecret,
+	})
+	a.users = usersStub{users: map[string]int{"ana": 2, "root": 3}}
+	admin := signHS256(hs256, claims(map[string]interface{}{"sub": "root", "role": "admin"}), []byte(testSecret))
+
+	tests := []struct {
+		name       string
+		credential string
+		want       domain.Identity
+		wantErr    bool
+		noCreds    bool
+	}{
+		{name: "API key", credential: "llave-lectura", want: domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}},
+		{name: "JWT", credential: admin, want: domain.Identity{UserID: 3, Username: "root", Role: domain.RoleAdmin}},
+		{name: "espacios alrededor", credential: " llave-lectura\n", want: domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}},
+		{name: "JWT falsificado", credential: signHS256(hs256, claims(nil), []byte("otro")), wantErr: true},
+		{name: "API key desconocida", credential: "llave", wantErr: true},
+		{name: "vacía", credential: "  ", noCreds: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := a.NewSession(context.Background(), tt.credential)
+			switch {
+			case tt.noCreds:
+				if !errors.Is(err, ErrNoCredentials) {
+					t.Fatalf("error %v, se esperaba ErrNoCredentials", err)
+				}
+			case tt.wantErr:
+				var appErr *domain.AppError
+				if !errors.As(err, &appErr) || appErr.Kind != domain.ErrKindUnauthorized {
+					t.Fatalf("error %v, se esperaba un error 401", err)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				// La cookie no lleva la credencial
+				if strings.Contains(session.Value, strings.TrimSpace(tt.credential)) {
+					t.Errorf("la sesión contiene la credencial: %q", session.Value)
+				}
+				id, err := a.AuthenticateSession(context.Background(), session.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id != tt.want {
+					t.Errorf("identidad %+v, se esperaba %+v", id, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticateSession(t *testing.T) {
+	cfg := config.AuthConfig{
+		APIKeys:       []config.APIKey{{Key: "llave-lectura", Username: "ana", Role: "reader"}, {Key: "llave-admin", Username: "root", Role: "admin"}},
+		JWTSecret:     testSecret,
+		SessionSecret: "secreto-de-sesion",
+	}
+	a := newTestAuthenticator(t, cfg)
+	a.users = usersStub{users: map[string]int{"ana": 2, "root": 3}}
+	ctx := context.Background()
+	newSession := func(a *Authenticator, credential string) Session {
+		t.Helper()
+		s, err := a.NewSession(ctx, credential)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	unauthorized := func(name string, a *Authenticator, value string) {
+		t.Helper()
+		var appErr *domain.AppError
+		if _, err := a.AuthenticateSession(ctx, value); !errors.As(err, &appErr) || appErr.Kind != domain.ErrKindUnauthorized {
+			t.Errorf("%s: error %v, se esperaba un error 401", name, err)
+		}
+	}
+
+	reader := newSession(a, "llave-lectura")
+	if want := testNow.Add(SessionTTL); !reader.ExpiresAt.Equal(want) {
+		t.Errorf("expira %v, se esperaba %v", reader.ExpiresAt, want)
+	}
+
+	// Cambiar el rol del contenido invalida la firma
+	payload, sig, _ := strings.Cut(reader.Value, ".")
+	raw, _ := base64.RawURLEncoding.DecodeString(payload)
+	forged := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(raw), `"reader"`, `"admin"`, 1)))
+	unauthorized("rol alterado", a, forged+"."+sig)
+	unauthorized("sin firma", a, payload)
+	unauthorized("credencial en la cookie", a, "llave-lectura")
+
+	// Otra llave de sesión (otro SESSION_SECRET o un reinicio sin él) no reconoce la cookie
+	other := newTestAuthenticator(t, config.AuthConfig{APIKeys: cfg.APIKeys, SessionSecret: "otro"})
+	other.users = a.users
+	unauthorized("otra llave de sesión", other, reader.Value)
+
+	// Quitar la API key de la configuración cierra las sesiones que se abrieron con ella
+	revoked := newTestAuthenticator(t, config.AuthConfig{APIKeys: cfg.APIKeys[1:], SessionSecret: cfg.SessionSecret})
+	revoked.users = a.users
+	unauthorized("API key revocada", revoked, reader.Value)
+	if _, err := revoked.AuthenticateSession(ctx, newSession(a, "llave-admin").Value); err != nil {
+		t.Errorf("API key vigente: %v", err)
+	}
+
+	a.now = func() time.Time { return testNow.Add(SessionTTL) }
+	unauthorized("expirada", a, reader.Value)
+
+	// Con un JWT la sesión no dura más que el token (exp una hora después de testNow)
+	a.now = func() time.Time { return testNow }
+	jwt := newSession(a, signHS256(hs256, claims(nil), []byte(testSecret)))
+	if want := testNow.Add(time.Hour); !jwt.ExpiresAt.Equal(want) {
+		t.Errorf("sesión con JWT expira %v, se esperaba %v", jwt.ExpiresAt, want)
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Tolerancia para relojes desfasados al validar exp y nbf
+const clockSkew = 30 * time.Second
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+// jwtClaims son los claims que se leen del token. sub es el username y role el rol (reader por defecto)
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Role      domain.Role `json:"role"`
+	Issuer    string      `json:"iss"`
+	Audience  audience    `json:"aud"`
+	ExpiresAt *float64    `json:"exp"`
+	NotBefore *float64    `json:"nbf"`
+}
+
+// audience acepta aud como string o como arreglo (RFC 7519, sección 4.1.3)
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("aud debe ser string o arreglo de strings")
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(v string) bool {
+	for _, aud := range a {
+		if aud == v {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyJWT comprueba firma y vigencia. Solo se aceptan los algoritmos con llave configurada,
+// así un token "alg: none" o HS256 firmado con la llave pública RSA se rechaza
+func (a *Authenticator) verifyJWT(token string) (jwtClaims, error) {
+	var claims jwtClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("formato JWT inválido")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims, fmt.Errorf("header: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, errors.New("firma mal codificada")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "HS256":
+		if a.hmacSecret == nil {
+			return claims, errors.New("HS256 no está habilitado")
+		}
+		mac := hmac.New(sha256.New, a.hmacSecret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return claims, errors.New("firma inválida")
+		}
+	case "RS256":
+		if a.rsaKey == nil {
+			return claims, errors.New("RS256 no está habilitado")
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(a.rsaKey, crypto.SHA256, digest[:], sig); err != nil {
+			return claims, errors.New("firma inválida")
+		}
+	default:
+		return claims, fmt.Errorf("algoritmo %q no soportado", header.Alg)
+	}
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return claims, fmt.Errorf("claims: %v", err)
+	}
+	return claims, a.validateClaims(&claims)
+}
+
+func (a *Authenticator) validateClaims(c *jwtClaims) error {
+	now := a.now()
+	if c.ExpiresAt == nil {
+		return errors.New("falta exp")
+	}
+	if now.After(time.Unix(int64(*c.ExpiresAt), 0).Add(clockSkew)) {
+		return errors.New("token expirado")
+	}
+	if c.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(int64(*c.NotBefore), 0)) {
+		return errors.New("token aún no vigente")
+	}
+	if a.issuer != "" && c.Issuer != a.issuer {
+		return errors.New("iss no coincide")
+	}
+	if a.audience != "" && !c.Audience.contains(a.audience) {
+		return errors.New("aud no coincide")
+	}
+	if c.Subject == "" {
+		return errors.New("falta sub")
+	}
+	if c.Role == "" {
+		c.Role = domain.RoleReader
+	}
+	if !c.Role.IsValid() {
+		return fmt.Errorf("rol %q desconocido", c.Role)
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New("base64 inválido")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("JSON inválido")
+	}
+	return nil
+}
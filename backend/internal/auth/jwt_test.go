@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+const testSecret = "secreto-de-prueba"
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// Llaves RSA generadas una vez: la configurada en el autenticador y otra ajena
+var (
+	rsaKey   = mustRSAKey()
+	otherKey = mustRSAKey()
+)
+
+func mustRSAKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func publicPEM(key *rsa.PrivateKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func segment(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signHS256 firma con HMAC-SHA256; el header puede declarar cualquier alg para probar falsificaciones
+func signHS256(header, claims map[string]interface{}, secret []byte) string {
+	signed := segment(header) + "." + segment(claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(header, claims map[string]interface{}, key *rsa.PrivateKey) string {
+	signed := segment(header) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+var (
+	hs256 = map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	rs256 = map[string]interface{}{"alg": "RS256", "typ": "JWT"}
+)
+
+// claims válidos con exp a una hora de testNow; overrides reemplaza o borra (valor nil) claims
+func claims(overrides map[string]interface{}) map[string]interface{} {
+	c := map[string]interface{}{"sub": "ana", "exp": testNow.Add(time.Hour).Unix()}
+	for k, v := range overrides {
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+func newTestAuthenticator(t *testing.T, cfg config.AuthConfig) *Authenticator {
+	t.Helper()
+	a, err := New(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return testNow }
+	return a
+}
+
+func TestVerifyJWT(t *testing.T) {
+	hsOnly := config.AuthConfig{JWTSecret: testSecret}
+	rsOnly := config.AuthConfig{JWTPublicKeyPEM: publicPEM(rsaKey)}
+	both := config.AuthConfig{JWTSecret: testSecret, JWTPublicKeyPEM: publicPEM(rsaKey)}
+	withIssAud := config.AuthConfig{JWTSecret: testSecret, JWTIssuer: "idp", JWTAudience: "spotify-data"}
+	secret := []byte(testSecret)
+
+	valid := signHS256(hs256, claims(nil), secret)
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name     string
+		cfg      config.AuthConfig
+		token    string
+		wantErr  string // Vacío si el token debe aceptarse
+		wantRole domain.Role
+	}{
+		// Tokens válidos
+		{name: "HS256 válido, rol por defecto reader", cfg: hsOnly, token: valid, wantRole: domain.RoleReader},
+		{name: "RS256 válido", cfg: rsOnly, token: signRS256(rs256, claims(nil), rsaKey), wantRole: domain.RoleReader},
+		{name: "rol admin", cfg: both, token: signHS256(hs256, claims(map[string]interface{}{"role": "admin"}), secret), wantRole: domain.RoleAdmin},
+		{name: "ambos algoritmos configurados, RS256", cfg: both, token: signRS256(rs256, claims(nil), rsaKey), wantRole: domain.RoleReader},
+		{name: "iss y aud coinciden", cfg: withIssAud, token: signHS256(hs256, claims(map[string]interface{}{"iss": "idp", "aud": "spotify-data"}), secret), wantRole: domain.RoleReader},
+		{name: "aud como arreglo", cfg: withIssAud, token: signHS256(hs256, claims(map[string]interface{}{"iss": "idp", "aud": []string{"otra", "spotify-data"}}), secret), wantRole: domain.RoleReader},
+
+		// Algoritmo: solo los que tienen llave configurada
+		{name: "alg none sin firma", cfg: both, token: segment(map[string]interface{}{"alg": "none"}) + "." + parts[1] + ".", wantErr: "no soportado"},
+		{name: "alg none con la firma de un token válido", cfg: hsOnly, token: segment(map[string]interface{}{"alg": "none"}) + "." + parts[1] + "." + parts[2], wantErr: "no soportado"},
+		{name: "alg en minúsculas", cfg: hsOnly, token: signHS256(map[string]interface{}{"alg": "hs256"}, claims(nil), secret), wantErr: "no soportado"},
+		{name: "HS512 no soportado", cfg: hsOnly, token: signHS256(map[string]interface{}{"alg": "HS512"}, claims(nil), secret), wantErr: "no soportado"},
+		{name: "HS256 sin secreto configurado", cfg: rsOnly, token: valid, wantErr: "HS256 no está habilitado"},
+		{name: "RS256 sin llave configurada", cfg: hsOnly, token: signRS256(rs256, claims(nil), rsaKey), wantErr: "RS256 no está habilitado"},
+		{name: "confusión HS/RS: HMAC con la llave pública", cfg: rsOnly, token: signHS256(hs256, claims(nil), publicPEM(rsaKey)), wantErr: "HS256 no está habilitado"},
+		{name: "confusión HS/RS con ambos configurados", cfg: both, token: signHS256(rs256, claims(nil), publicPEM(rsaKey)), wantErr: "firma inválida"},
+
+		// Firma
+		{name: "secreto equivocado", cfg: hsOnly, token: signHS256(hs256, claims(nil), []byte("otro")), wantErr: "firma inválida"},
+		{name: "llave RSA ajena", cfg: rsOnly, token: signRS256(rs256, claims(nil), otherKey), wantErr: "firma inválida"},
+		{name: "claims alterados", cfg: hsOnly, token: parts[0] + "." + segment(claims(map[string]interface{}{"role": "admin"})) + "." + parts[2], wantErr: "firma inválida"},
+		{name: "firma mal codificada", cfg: hsOnly, token: parts[0] + "." + parts[1] + ".%%", wantErr: "firma mal codificada"},
+		{name: "dos segmentos", cfg: hsOnly, token: parts[0] + "." + parts[1], wantErr: "formato JWT inválido"},
+		{name: "header que no es JSON", cfg: hsOnly, token: "bm8." + parts[1] + "." + parts[2], wantErr: "header"},
+
+		// Vigencia (tolerancia de 30s)
+		{name: "sin exp", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"exp": nil}), secret), wantErr: "falta exp"},
+		{name: "expirado hace 31s", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"exp": testNow.Add(-31 * time.Second).Unix()}), secret), wantErr: "token expirado"},
+		{name: "expirado hace 29s, dentro de la tolerancia", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"exp": testNow.Add(-29 * time.Second).Unix()}), secret), wantRole: domain.RoleReader},
+		{name: "nbf en 31s", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"nbf": testNow.Add(31 * time.Second).Unix()}), secret), wantErr: "aún no vigente"},
+		{name: "nbf en 29s, dentro de la tolerancia", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"nbf": testNow.Add(29 * time.Second).Unix()}), secret), wantRole: domain.RoleReader},
+		{name: "exp no numérico", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"exp": "mañana"}), secret), wantErr: "claims"},
+
+		// Claims
+		{name: "sin sub", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"sub": nil}), secret), wantErr: "falta sub"},
+		{name: "rol desconocido", cfg: hsOnly, token: signHS256(hs256, claims(map[string]interface{}{"role": "root"}), secret), wantErr: "rol"},
+		{name: "iss distinto", cfg: withIssAud, token: signHS256(hs256, claims(map[string]interface{}{"iss": "otro", "aud": "spotify-data"}), secret), wantErr: "iss no coincide"},
+		{name: "sin aud", cfg: withIssAud, token: signHS256(hs256, claims(map[string]interface{}{"iss": "idp"}), secret), wantErr: "aud no coincide"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, tt.cfg)
+			c, err := a.verifyJWT(tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("token rechazado: %v", err)
+			}
+			if c.Subject != "ana" || c.Role != tt.wantRole {
+				t.Errorf("sub=%q role=%q, se esperaba ana y %q", c.Subject, c.Role, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// SessionTTL es la duración máxima de una sesión del dashboard. Con un JWT la sesión no dura más que el token
+const SessionTTL = 12 * time.Hour
+
+// Session es el valor opaco de la cookie del dashboard: la credencial con que se ingresó nunca sale del servidor
+type Session struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// sessionClaims es el contenido firmado de la cookie
+type sessionClaims struct {
+	Username string      `json:"u"`
+	Role     domain.Role `json:"r"`
+	KeyHash  string      `json:"k,omitempty"` // API key con que se ingresó: quitarla de API_KEYS invalida la sesión
+	Expires  int64       `json:"e"`
+}
+
+// NewSession valida una API key o un JWT (el formulario de ingreso) y emite la sesión firmada
+func (a *Authenticator) NewSession(ctx context.Context, credential string) (Session, error) {
+	p, err := a.credential(credential)
+	if err != nil {
+		return Session{}, err
+	}
+	if _, err := a.identity(ctx, p); err != nil {
+		return Session{}, err
+	}
+
+	expires := a.now().Add(SessionTTL)
+	if !p.expires.IsZero() && p.expires.Before(expires) {
+		expires = p.expires
+	}
+	c := sessionClaims{Username: p.username, Role: p.role, Expires: expires.Unix()}
+	if p.keyHash != nil {
+		c.KeyHash = hex.EncodeToString(p.keyHash[:])
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return Session{}, domain.NewInternalError(err, "No se pudo crear la sesión")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return Session{Value: encoded + "." + a.signSession(encoded), ExpiresAt: time.Unix(c.Expires, 0)}, nil
+}
+
+// AuthenticateSession verifica la firma y la vigencia de la cookie y resuelve al usuario
+func (a *Authenticator) AuthenticateSession(ctx context.Context, value string) (domain.Identity, error) {
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(a.signSession(encoded))) {
+		return domain.Identity{}, domain.NewUnauthorizedError("Sesión inválida")
+	}
+	var c sessionClaims
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &c) != nil || !c.Role.IsValid() {
+		return domain.Identity{}, domain.NewUnauthorizedError("Sesión inválida")
+	}
+	if !a.now().Before(time.Unix(c.Expires, 0)) {
+		return domain.Identity{}, domain.NewUnauthorizedError("La sesión expiró")
+	}
+	if c.KeyHash != "" {
+		hash, err := hex.DecodeString(c.KeyHash)
+		if err != nil || len(hash) != sha256.Size {
+			return domain.Identity{}, domain.NewUnauthorizedError("Sesión inválida")
+		}
+		if _, ok := a.keys[[sha256.Size]byte(hash)]; !ok {
+			return domain.Identity{}, domain.NewUnauthorizedError("La API key de la sesión ya no es válida")
+		}
+	}
+	return a.identity(ctx, principal{username: c.Username, role: c.Role})
+}
+
+// SecureCookies indica si la cookie de sesión debe marcarse Secure aunque la petición llegue por HTTP
+// (TLS terminado en un proxy)
+func (a *Authenticator) SecureCookies() bool {
+	return a.secureCookies
+}
+
+func (a *Authenticator) signSession(encoded string) string {
+	mac := hmac.New(sha256.New, a.sessionKey)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
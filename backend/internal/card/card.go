@@ -0,0 +1,207 @@
+package card
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Tamaño de historia (9:16), el mismo que usan Instagram y WhatsApp
+const (
+	Width  = 1080
+	Height = 1920
+	margin = 90
+)
+
+// Cantidad de artistas y canciones en la tarjeta
+const topItems = 5
+
+// Theme define la paleta de la tarjeta. El fondo es un degradado vertical de Top a Bottom
+type Theme struct {
+	Name   string
+	Top    color.RGBA
+	Bottom color.RGBA
+	Text   color.RGBA
+	Muted  color.RGBA
+	Accent color.RGBA
+}
+
+var themes = []Theme{
+	{
+		Name: "dark", Top: rgb(0x19, 0x19, 0x19), Bottom: rgb(0x05, 0x05, 0x05),
+		Text: rgb(0xff, 0xff, 0xff), Muted: rgb(0xb3, 0xb3, 0xb3), Accent: rgb(0x1d, 0xb9, 0x54),
+	},
+	{
+		Name: "light", Top: rgb(0xfa, 0xfa, 0xf7), Bottom: rgb(0xe4, 0xe9, 0xe2),
+		Text: rgb(0x12, 0x12, 0x12), Muted: rgb(0x5c, 0x5c, 0x5c), Accent: rgb(0x12, 0x8a, 0x3e),
+	},
+	{
+		Name: "sunset", Top: rgb(0x2b, 0x10, 0x55), Bottom: rgb(0xd5, 0x3a, 0x6d),
+		Text: rgb(0xff, 0xf6, 0xe9), Muted: rgb(0xf3, 0xc9, 0xd6), Accent: rgb(0xff, 0xb3, 0x47),
+	},
+}
+
+// DefaultTheme se usa cuando no se indica theme
+const DefaultTheme = "dark"
+
+func rgb(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}
+}
+
+// ThemeNames lista los temas disponibles (para validar y documentar el parámetro)
+func ThemeNames() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func LookupTheme(name string) (Theme, bool) {
+	for _, t := range themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// text es un texto posicionado por su línea base. Anchor es "start" o "middle", como en SVG
+type text struct {
+	X, Y   float64
+	Size   float64
+	Bold   bool
+	Color  color.RGBA
+	Anchor string
+	Value  string
+}
+
+// rect es un rectángulo relleno, Opacity entre 0 y 1
+type rect struct {
+	X, Y, W, H float64
+	Color      color.RGBA
+	Opacity    float64
+}
+
+// layout es la tarjeta ya compuesta. SVG y PNG dibujan exactamente los mismos elementos
+type layout struct {
+	theme Theme
+	rects []rect
+	texts []text
+}
+
+var monthNames = []string{"Enero", "Febrero", "Marzo", "Abril", "Mayo", "Junio", "Julio",
+	"Agosto", "Septiembre", "Octubre", "Noviembre", "Diciembre"}
+
+var seasonNames = map[domain.Season]string{
+	domain.Summer: "Verano", domain.Autumn: "Otoño", domain.Winter: "Invierno", domain.Spring: "Primavera",
+}
+
+func periodTitle(p domain.WrappedPeriodDTO) string {
+	switch p.Type {
+	case domain.WrappedMonth:
+		if p.Month >= 1 && p.Month <= 12 {
+			return fmt.Sprintf("%s %d", monthNames[p.Month-1], p.Year)
+		}
+	case domain.WrappedSeason:
+		if name, ok := seasonNames[p.Season]; ok {
+			return fmt.Sprintf("%s %d", name, p.Year)
+		}
+	}
+	return strconv.Itoa(p.Year)
+}
+
+// favoriteTimeOfDay retorna el bloque horario con más reproducciones ("" si no hay datos)
+func favoriteTimeOfDay(habits []domain.HabitTimeDTO) string {
+	if len(habits) == 0 {
+		return ""
+	}
+	sorted := append([]domain.HabitTimeDTO(nil), habits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Count > sorted[j].Count })
+	return sorted[0].Label
+}
+
+// FormatThousands usa punto como separador de miles: 12345 -> 12.345
+func FormatThousands(n int) string {
+	s := strconv.Itoa(n)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// truncate corta por cantidad de caracteres; ambos renderizadores usan la misma regla
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
+
+// compose arma la tarjeta: periodo, minutos totales, top 5 artistas (con barra proporcional
+// a sus minutos), top 5 canciones y el momento del día favorito
+func compose(w domain.WrappedDTO, theme Theme) layout {
+	l := layout{theme: theme}
+	add := func(t text) {
+		if t.Anchor == "" {
+			t.Anchor = "start"
+		}
+		l.texts = append(l.texts, t)
+	}
+	center := float64(Width) / 2
+
+	add(text{X: center, Y: 170, Size: 40, Color: theme.Accent, Bold: true, Anchor: "middle", Value: "MI WRAPPED"})
+	add(text{X: center, Y: 260, Size: 76, Color: theme.Text, Bold: true, Anchor: "middle", Value: periodTitle(w.Period)})
+
+	add(text{X: center, Y: 450, Size: 128, Color: theme.Text, Bold: true, Anchor: "middle", Value: FormatThousands(int(w.Stats.TotalMinutes))})
+	add(text{X: center, Y: 515, Size: 38, Color: theme.Muted, Anchor: "middle", Value: "minutos escuchados"})
+	l.rects = append(l.rects, rect{X: center - 60, Y: 560, W: 120, H: 6, Color: theme.Accent, Opacity: 1})
+
+	// Top artistas
+	add(text{X: margin, Y: 680, Size: 42, Color: theme.Accent, Bold: true, Value: "Top artistas"})
+	var maxMinutes float64
+	for i, a := range w.TopArtists {
+		if i < topItems && a.MinutesPlayed > maxMinutes {
+			maxMinutes = a.MinutesPlayed
+		}
+	}
+	for i, a := range w.TopArtists {
+		if i == topItems {
+			break
+		}
+		y := 760 + float64(i)*76
+		if maxMinutes > 0 {
+			barW := (Width - 2*margin) * a.MinutesPlayed / maxMinutes
+			l.rects = append(l.rects, rect{X: margin, Y: y - 46, W: barW, H: 60, Color: theme.Accent, Opacity: 0.18})
+		}
+		add(text{X: margin + 20, Y: y, Size: 40, Color: theme.Accent, Bold: true, Value: strconv.Itoa(i + 1)})
+		add(text{X: margin + 80, Y: y, Size: 40, Color: theme.Text, Value: truncate(a.ArtistName, 34)})
+	}
+
+	// Top canciones: nombre y artista en dos líneas
+	add(text{X: margin, Y: 1200, Size: 42, Color: theme.Accent, Bold: true, Value: "Top canciones"})
+	for i, s := range w.TopSongs {
+		if i == topItems {
+			break
+		}
+		y := 1275 + float64(i)*100
+		add(text{X: margin + 20, Y: y, Size: 40, Color: theme.Accent, Bold: true, Value: strconv.Itoa(i + 1)})
+		add(text{X: margin + 80, Y: y, Size: 38, Color: theme.Text, Value: truncate(s.TrackName, 36)})
+		add(text{X: margin + 80, Y: y + 38, Size: 28, Color: theme.Muted, Value: truncate(s.ArtistName, 48)})
+	}
+
+	if tod := favoriteTimeOfDay(w.TimeOfDay); tod != "" {
+		add(text{X: center, Y: 1795, Size: 36, Color: theme.Muted, Anchor: "middle", Value: "Momento favorito: " + tod})
+	}
+	add(text{X: center, Y: 1865, Size: 28, Color: theme.Muted, Anchor: "middle", Value: "My Spotify Data"})
+	return l
+}
@@ -0,0 +1,195 @@
+package card
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// sampleWrapped incluye nombres con caracteres especiales de XML y más de topItems entradas
+func sampleWrapped() domain.WrappedDTO {
+	w := domain.WrappedDTO{
+		Period: domain.WrappedPeriodDTO{Type: domain.WrappedMonth, Year: 2024, Month: 3},
+		Stats:  domain.TotalStatsDTO{TotalMinutes: 12345.6},
+		TimeOfDay: []domain.HabitTimeDTO{
+			{Label: "Mañana", Count: 10}, {Label: "Noche", Count: 40}, {Label: "Tarde", Count: 40},
+		},
+	}
+	for i := 0; i < 7; i++ {
+		a := domain.ArtistRankingDTO{ArtistName: fmt.Sprintf("Simon & Garfunkel <%d>", i)}
+		a.MinutesPlayed = float64(100 - i*10)
+		w.TopArtists = append(w.TopArtists, a)
+		w.TopSongs = append(w.TopSongs, domain.SongRankingDTO{TrackName: fmt.Sprintf(`"Canción" %d`, i), ArtistName: "Artista'"})
+	}
+	return w
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := map[int]string{
+		0:          "0",
+		7:          "7",
+		999:        "999",
+		1000:       "1.000",
+		12345:      "12.345",
+		100000:     "100.000",
+		1234567890: "1.234.567.890",
+	}
+	for n, want := range tests {
+		if got := FormatThousands(n); got != want {
+			t.Errorf("FormatThousands(%d) = %q, se esperaba %q", n, got, want)
+		}
+	}
+}
+
+func TestPeriodTitle(t *testing.T) {
+	tests := []struct {
+		period domain.WrappedPeriodDTO
+		want   string
+	}{
+		{domain.WrappedPeriodDTO{Type: domain.WrappedYear, Year: 2023}, "2023"},
+		{domain.WrappedPeriodDTO{Type: domain.WrappedMonth, Year: 2024, Month: 1}, "Enero 2024"},
+		{domain.WrappedPeriodDTO{Type: domain.WrappedMonth, Year: 2024, Month: 12}, "Diciembre 2024"},
+		{domain.WrappedPeriodDTO{Type: domain.WrappedMonth, Year: 2024, Month: 13}, "2024"},
+		{domain.WrappedPeriodDTO{Type: domain.WrappedSeason, Year: 2024, Season: domain.Autumn}, "Otoño 2024"},
+		{domain.WrappedPeriodDTO{Type: domain.WrappedSeason, Year: 2024, Season: "monzón"}, "2024"},
+	}
+	for _, tt := range tests {
+		if got := periodTitle(tt.period); got != tt.want {
+			t.Errorf("periodTitle(%+v) = %q, se esperaba %q", tt.period, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("Canción", 7); got != "Canción" {
+		t.Errorf("texto del largo exacto: %q", got)
+	}
+	// Se cuentan caracteres, no bytes: una tilde no debe partir una runa
+	if got := truncate("Canción larga", 8); got != "Canción…" {
+		t.Errorf("truncate = %q", got)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	l := compose(sampleWrapped(), themes[0])
+
+	var values []string
+	for _, tx := range l.texts {
+		values = append(values, tx.Value)
+		if tx.Anchor != "start" && tx.Anchor != "middle" {
+			t.Errorf("%q: anchor %q", tx.Value, tx.Anchor)
+		}
+		if tx.X < 0 || tx.X > Width || tx.Y < 0 || tx.Y > Height {
+			t.Errorf("%q fuera de la tarjeta: (%v, %v)", tx.Value, tx.X, tx.Y)
+		}
+	}
+	joined := strings.Join(values, "\n")
+	for _, want := range []string{"Marzo 2024", "12.345", "Simon & Garfunkel <4>", "Momento favorito: Noche"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("la tarjeta no incluye %q", want)
+		}
+	}
+	// Solo el top 5: ni el sexto artista ni la sexta canción
+	if strings.Contains(joined, "<5>") || strings.Contains(joined, `"Canción" 5`) {
+		t.Errorf("la tarjeta incluye más de %d artistas o canciones", topItems)
+	}
+
+	// Una barra por artista, proporcional al primero, más el separador bajo los minutos
+	if len(l.rects) != topItems+1 {
+		t.Fatalf("%d rectángulos, se esperaban %d", len(l.rects), topItems+1)
+	}
+	first, last := l.rects[1], l.rects[topItems]
+	if first.W != Width-2*margin || last.W >= first.W {
+		t.Errorf("barras: primera %v, última %v", first.W, last.W)
+	}
+
+	empty := compose(domain.WrappedDTO{Period: domain.WrappedPeriodDTO{Year: 2024}}, themes[0])
+	for _, tx := range empty.texts {
+		if strings.HasPrefix(tx.Value, "Momento favorito") {
+			t.Errorf("sin hábitos no debe mostrarse el momento favorito")
+		}
+	}
+}
+
+func TestRenderSVGIsWellFormed(t *testing.T) {
+	for _, name := range ThemeNames() {
+		t.Run(name, func(t *testing.T) {
+			theme, _ := LookupTheme(name)
+			var buf bytes.Buffer
+			if err := RenderSVG(&buf, sampleWrapped(), theme); err != nil {
+				t.Fatal(err)
+			}
+
+			svg := buf.String()
+			var texts []string
+			dec := xml.NewDecoder(strings.NewReader(svg))
+			var root string
+			for {
+				tok, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("SVG mal formado: %v", err)
+				}
+				switch tok := tok.(type) {
+				case xml.StartElement:
+					if root == "" {
+						root = tok.Name.Local
+					}
+				case xml.CharData:
+					texts = append(texts, string(tok))
+				}
+			}
+			if root != "svg" {
+				t.Errorf("elemento raíz %q", root)
+			}
+			// Los caracteres especiales se escapan y vuelven intactos al leer el XML
+			if joined := strings.Join(texts, "\n"); !strings.Contains(joined, "Simon & Garfunkel <0>") || !strings.Contains(joined, `"Canción" 0`) {
+				t.Errorf("textos escapados incorrectamente: %q", joined)
+			}
+			if !strings.Contains(svg, hex(theme.Accent)) {
+				t.Errorf("el SVG no usa el color de acento del tema")
+			}
+		})
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	for _, name := range ThemeNames() {
+		t.Run(name, func(t *testing.T) {
+			theme, _ := LookupTheme(name)
+			var buf bytes.Buffer
+			if err := RenderPNG(&buf, sampleWrapped(), theme); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("PNG inválido: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+				t.Errorf("tamaño %dx%d, se esperaba %dx%d", b.Dx(), b.Dy(), Width, Height)
+			}
+			// Esquina superior: el inicio del degradado del tema
+			r, g, b, _ := img.At(0, 0).RGBA()
+			if uint8(r>>8) != theme.Top.R || uint8(g>>8) != theme.Top.G || uint8(b>>8) != theme.Top.B {
+				t.Errorf("fondo (0,0) = %d,%d,%d, se esperaba %v", r>>8, g>>8, b>>8, theme.Top)
+			}
+		})
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	if _, ok := LookupTheme(DefaultTheme); !ok {
+		t.Errorf("el tema por defecto %q no existe", DefaultTheme)
+	}
+	if _, ok := LookupTheme("neon"); ok {
+		t.Errorf("se aceptó un tema desconocido")
+	}
+}
@@ -0,0 +1,95 @@
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Las fuentes Go vienen embebidas en golang.org/x/image, el binario no depende de fuentes del sistema
+var (
+	regularFont = mustParseFont(goregular.TTF)
+	boldFont    = mustParseFont(gobold.TTF)
+)
+
+func mustParseFont(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(fmt.Sprintf("fuente embebida inválida: %v", err))
+	}
+	return f
+}
+
+// RenderPNG rasteriza la misma tarjeta que RenderSVG
+func RenderPNG(w io.Writer, wrapped domain.WrappedDTO, theme Theme) error {
+	l := compose(wrapped, theme)
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+
+	// Degradado vertical, una línea a la vez
+	for y := 0; y < Height; y++ {
+		c := lerp(theme.Top, theme.Bottom, float64(y)/float64(Height-1))
+		draw.Draw(img, image.Rect(0, y, Width, y+1), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	for _, r := range l.rects {
+		c := color.NRGBA{R: r.Color.R, G: r.Color.G, B: r.Color.B, A: uint8(math.Round(r.Opacity * 255))}
+		bounds := image.Rect(int(r.X), int(r.Y), int(math.Round(r.X+r.W)), int(math.Round(r.Y+r.H)))
+		draw.Draw(img, bounds, image.NewUniform(c), image.Point{}, draw.Over)
+	}
+
+	// Una Face por tamaño y peso; no son seguras para uso concurrente, por eso se crean en cada render
+	faces := map[[2]int]font.Face{}
+	defer func() {
+		for _, f := range faces {
+			f.Close()
+		}
+	}()
+	for _, t := range l.texts {
+		face, err := faceFor(faces, t.Size, t.Bold)
+		if err != nil {
+			return err
+		}
+		d := &font.Drawer{Dst: img, Src: image.NewUniform(t.Color), Face: face}
+		x := t.X
+		if t.Anchor == "middle" {
+			x -= float64(d.MeasureString(t.Value)) / 64 / 2
+		}
+		d.Dot = fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(t.Y * 64)}
+		d.DrawString(t.Value)
+	}
+
+	return (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(w, img)
+}
+
+func faceFor(faces map[[2]int]font.Face, size float64, bold bool) (font.Face, error) {
+	key := [2]int{int(size), 0}
+	f := regularFont
+	if bold {
+		key[1], f = 1, boldFont
+	}
+	if face, ok := faces[key]; ok {
+		return face, nil
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("error al crear la fuente: %v", err)
+	}
+	faces[key] = face
+	return face, nil
+}
+
+func lerp(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t)) }
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 0xff}
+}
@@ -0,0 +1,51 @@
+package card
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Familias de respaldo por si el visor no tiene Go, la fuente que usa el PNG
+const svgFontFamily = "Go, 'Helvetica Neue', Helvetica, Arial, sans-serif"
+
+// RenderSVG escribe la tarjeta del wrapped como SVG
+func RenderSVG(w io.Writer, wrapped domain.WrappedDTO, theme Theme) error {
+	l := compose(wrapped, theme)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, Width, Height, Width, Height)
+	fmt.Fprintf(b, `<defs><linearGradient id="bg" x1="0" y1="0" x2="0" y2="1">`+
+		`<stop offset="0" stop-color="%s"/><stop offset="1" stop-color="%s"/></linearGradient></defs>`,
+		hex(theme.Top), hex(theme.Bottom))
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="url(#bg)"/>`, Width, Height)
+
+	for _, r := range l.rects {
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="%.2f"/>`,
+			r.X, r.Y, r.W, r.H, hex(r.Color), r.Opacity)
+	}
+
+	fmt.Fprintf(b, `<g font-family="%s">`, svgFontFamily)
+	for _, t := range l.texts {
+		weight := "normal"
+		if t.Bold {
+			weight = "bold"
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="%.0f" font-weight="%s" fill="%s" text-anchor="%s">`,
+			t.X, t.Y, t.Size, weight, hex(t.Color), t.Anchor)
+		if err := xml.EscapeText(b, []byte(t.Value)); err != nil {
+			return err
+		}
+		b.WriteString("</text>")
+	}
+	b.WriteString("</g></svg>")
+	return b.Flush()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +18,53 @@ import (
 type AppConfig struct {
 	Port  string
 	DBUrl string
+	// DBStatementTimeout corta las consultas lentas (0 = sin límite). Por defecto es menor que el
+	// WriteTimeout del servidor, así alcanza a responderse el error 504
+	DBStatementTimeout time.Duration
+	Auth               AuthConfig
+	CORS               CORSConfig
+}
+
+// CORSConfig define qué orígenes del navegador pueden usar la API. "*" permite cualquiera
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int // Segundos que el navegador puede cachear el preflight (0 = no se envía)
+}
+
+// AllowsAnyOrigin indica si la lista incluye el comodín "*"
+func (c CORSConfig) AllowsAnyOrigin() bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthConfig agrupa las credenciales aceptadas por la API. Sin API keys ni JWT la autenticación
+// queda deshabilitada y todas las peticiones se atienden como el usuario por defecto
+type AuthConfig struct {
+	APIKeys         []APIKey
+	JWTSecret       string // Firma HS256
+	JWTPublicKeyPEM []byte // Llave pública para RS256
+	JWTIssuer       string // iss esperado (opcional)
+	JWTAudience     string // aud esperado (opcional)
+	SessionSecret   string // Firma las cookies del dashboard (opcional, sin ella se genera al arrancar)
+	SecureCookies   bool   // Marca la cookie Secure aunque la petición llegue por HTTP (TLS en un proxy)
+}
+
+// APIKey es una llave estática asociada a un usuario y un rol
+type APIKey struct {
+	Key      string
+	Username string
+	Role     string
+}
+
+func (a AuthConfig) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.JWTSecret != "" || len(a.JWTPublicKeyPEM) > 0
 }
 
 // Load lee las variables de entorno y construye la configuración
@@ -38,10 +90,146 @@ func Load() *AppConfig {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable&timezone=America/Santiago",
 		dbUser, dbPass, dbHost, dbPort, dbName)
 
+	statementTimeout, err := parseStatementTimeout(os.Getenv("DB_STATEMENT_TIMEOUT"))
+	if err != nil {
+		log.Fatalf("Error Crítico: %v", err)
+	}
+
 	return &AppConfig{
-		Port:  port,
-		DBUrl: dsn,
+		Port:               port,
+		DBUrl:              dsn,
+		DBStatementTimeout: statementTimeout,
+		Auth:               loadAuth(),
+		CORS:               loadCORS(),
+	}
+}
+
+// DefaultStatementTimeout aplica si DB_STATEMENT_TIMEOUT no viene
+const DefaultStatementTimeout = 8 * time.Second
+
+// parseStatementTimeout lee DB_STATEMENT_TIMEOUT como duración de Go (ej. 5s, 1m); 0 lo deshabilita
+func parseStatementTimeout(raw string) (time.Duration, error) {
+	if raw == "" {
+		return DefaultStatementTimeout, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("DB_STATEMENT_TIMEOUT debe ser una duración no negativa (ej. 8s), se recibió %q", raw)
+	}
+	return d, nil
+}
+
+// loadCORS lee CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS (listas separadas por coma),
+// CORS_ALLOW_CREDENTIALS y CORS_MAX_AGE. Sin configurar se mantiene el comportamiento abierto (origen *)
+func loadCORS() CORSConfig {
+	cors, err := parseCORS(os.Getenv)
+	if err != nil {
+		log.Fatalf("Error Crítico: %v", err)
+	}
+	return cors
+}
+
+// parseCORS arma la configuración CORS leyendo las variables con getenv
+func parseCORS(getenv func(string) string) (CORSConfig, error) {
+	cors := CORSConfig{
+		AllowedOrigins: splitList(getenv("CORS_ALLOWED_ORIGINS"), "*"),
+		AllowedMethods: splitList(getenv("CORS_ALLOWED_METHODS"), "GET,POST,PUT,DELETE,OPTIONS"),
+		AllowedHeaders: splitList(getenv("CORS_ALLOWED_HEADERS"), "Content-Type,Authorization,X-API-Key"),
+	}
+	// Los navegadores comparan el origen exacto (esquema://host[:puerto]), sin barra final
+	for i, o := range cors.AllowedOrigins {
+		cors.AllowedOrigins[i] = strings.ToLower(strings.TrimSuffix(o, "/"))
+	}
+
+	if v := getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return cors, errors.New("CORS_ALLOW_CREDENTIALS debe ser true o false")
+		}
+		cors.AllowCredentials = b
+	}
+	// La especificación CORS prohíbe credenciales con origen comodín
+	if cors.AllowCredentials && cors.AllowsAnyOrigin() {
+		return cors, errors.New("CORS_ALLOW_CREDENTIALS=true requiere listar los orígenes en CORS_ALLOWED_ORIGINS (no *)")
+	}
+
+	if v := getenv("CORS_MAX_AGE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cors, errors.New("CORS_MAX_AGE debe ser un número de segundos")
+		}
+		cors.MaxAge = n
+	}
+	return cors, nil
+}
+
+// splitList separa un valor por comas, con un valor por defecto si viene vacío
+func splitList(val, fallback string) []string {
+	if val == "" {
+		val = fallback
+	}
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// loadAuth lee API_KEYS ("llave:usuario[:rol],..."), JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE,
+// JWT_ISSUER, JWT_AUDIENCE, SESSION_SECRET y SESSION_COOKIE_SECURE. El rol por defecto de una API key es reader
+func loadAuth() AuthConfig {
+	auth := AuthConfig{
+		JWTSecret:     os.Getenv("JWT_HS256_SECRET"),
+		JWTIssuer:     os.Getenv("JWT_ISSUER"),
+		JWTAudience:   os.Getenv("JWT_AUDIENCE"),
+		SessionSecret: os.Getenv("SESSION_SECRET"),
+	}
+
+	if v := os.Getenv("SESSION_COOKIE_SECURE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatal("Error Crítico: SESSION_COOKIE_SECURE debe ser true o false")
+		}
+		auth.SecureCookies = b
+	}
+
+	keys, err := parseAPIKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		log.Fatalf("Error Crítico: %v", err)
+	}
+	auth.APIKeys = keys
+
+	if path := os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Error Crítico: no se pudo leer JWT_RS256_PUBLIC_KEY_FILE: %v", err)
+		}
+		auth.JWTPublicKeyPEM = pem
+	}
+	return auth
+}
+
+// parseAPIKeys interpreta "llave:usuario[:rol],...". El rol se valida al construir el autenticador
+func parseAPIKeys(raw string) ([]APIKey, error) {
+	var keys []APIKey
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+			return nil, errors.New("API_KEYS debe tener el formato llave:usuario[:rol]")
+		}
+		key := APIKey{Key: parts[0], Username: parts[1], Role: "reader"}
+		if len(parts) == 3 {
+			key.Role = parts[2]
+		}
+		keys = append(keys, key)
 	}
+	return keys, nil
 }
 
 // getEnvOrFatal asegura que la variable exista, si no, mata la aplicación
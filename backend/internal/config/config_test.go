@@ -0,0 +1,106 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []APIKey
+		wantErr bool
+	}{
+		{raw: "", want: nil},
+		{raw: "abc:ana", want: []APIKey{{Key: "abc", Username: "ana", Role: "reader"}}},
+		{raw: " abc:ana:admin , def:luis ,", want: []APIKey{
+			{Key: "abc", Username: "ana", Role: "admin"},
+			{Key: "def", Username: "luis", Role: "reader"},
+		}},
+		{raw: "abc", wantErr: true},
+		{raw: "abc:", wantErr: true},
+		{raw: ":ana", wantErr: true},
+		{raw: "abc:ana:", wantErr: true},
+		{raw: "abc:ana:admin:extra", wantErr: true},
+		{raw: "abc:ana,def", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseAPIKeys(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAPIKeys(%q): error %v", tt.raw, err)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAPIKeys(%q) = %+v, se esperaba %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseCORS(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	def, err := parseCORS(env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !def.AllowsAnyOrigin() || def.AllowCredentials || def.MaxAge != 0 || len(def.AllowedMethods) == 0 {
+		t.Errorf("configuración por defecto inesperada: %+v", def)
+	}
+
+	cfg, err := parseCORS(env(map[string]string{
+		"CORS_ALLOWED_ORIGINS":   "https://App.example.com/, http://localhost:5173",
+		"CORS_ALLOWED_METHODS":   "GET, POST",
+		"CORS_ALLOW_CREDENTIALS": "true",
+		"CORS_MAX_AGE":           "600",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "http://localhost:5173"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   def.AllowedHeaders,
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("parseCORS = %+v, se esperaba %+v", cfg, want)
+	}
+
+	invalid := []map[string]string{
+		{"CORS_ALLOW_CREDENTIALS": "true"}, // Credenciales con el origen * por defecto
+		{"CORS_ALLOW_CREDENTIALS": "true", "CORS_ALLOWED_ORIGINS": "https://a.example.com,*"},
+		{"CORS_ALLOW_CREDENTIALS": "quizás", "CORS_ALLOWED_ORIGINS": "https://a.example.com"},
+		{"CORS_MAX_AGE": "-1"},
+		{"CORS_MAX_AGE": "10m"},
+	}
+	for _, vars := range invalid {
+		if _, err := parseCORS(env(vars)); err == nil {
+			t.Errorf("parseCORS(%v) no falló", vars)
+		}
+	}
+}
+
+func TestParseStatementTimeout(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "", want: DefaultStatementTimeout},
+		{raw: "15s", want: 15 * time.Second},
+		{raw: "1m30s", want: 90 * time.Second},
+		{raw: "0", want: 0},
+		{raw: "-1s", wantErr: true},
+		{raw: "8", wantErr: true}, // Sin unidad es ambiguo
+	}
+	for _, tt := range tests {
+		got, err := parseStatementTimeout(tt.raw)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parseStatementTimeout(%q) = %v, %v", tt.raw, got, err)
+		}
+	}
+}
@@ -3,14 +3,26 @@ package database
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// Abre conexion, hace ping y retorna pool de conexiones
-func NewPostgresConnection(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+// Abre conexion, hace ping y retorna pool de conexiones.
+// statementTimeout > 0 se aplica a cada conexión: Postgres cancela la consulta (código 57014)
+// y la API responde 504 en vez de mantener la petición abierta
+func NewPostgresConnection(ctx context.Context, dsn string, statementTimeout time.Duration) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la configuración de la base de datos: %v", err)
+	}
+	if statementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(statementTimeout.Milliseconds(), 10)
+	}
+
 	// pgxpool maneja el conjunto de conexiones abiertas
-	pool, err := pgxpool.New(ctx, dsn)
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("error al crear el pool de conexiones: %v", err)
 	}
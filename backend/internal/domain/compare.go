@@ -0,0 +1,38 @@
+package domain
+
+// Comparación de gustos entre dos usuarios (compare-users). Salvo para un admin, las métricas del
+// otro usuario se omiten y su only_ queda en null: solo se ve qué tienen en común, no cuánto lo escucha
+type UserComparisonDTO struct {
+	UserA string `json:"user_a"`
+	UserB string `json:"user_b"`
+	// Superposición ponderada 0-100: suma, por artista compartido, de la menor de las dos
+	// proporciones de minutos. 100 = mismo reparto de minutos entre los mismos artistas
+	Compatibility     float64            `json:"compatibility"`
+	SharedArtistCount int                `json:"shared_artist_count"`
+	SharedTrackCount  int                `json:"shared_track_count"`
+	SharedArtists     []SharedArtistDTO  `json:"shared_artists"`
+	SharedTracks      []SharedTrackDTO   `json:"shared_tracks"`
+	OnlyA             []ArtistRankingDTO `json:"only_a"` // Artistas más escuchados por A que B nunca escuchó
+	OnlyB             []ArtistRankingDTO `json:"only_b"`
+	TopSharedTrack    *SharedTrackDTO    `json:"top_shared_track"` // La canción que ambos escucharon más (nil si no comparten ninguna)
+}
+
+// Artista escuchado por ambos usuarios. Share es la proporción de minutos de cada uno
+type SharedArtistDTO struct {
+	ArtistName string  `json:"artist_name"`
+	MinutesA   float64 `json:"minutes_a,omitempty"`
+	MinutesB   float64 `json:"minutes_b,omitempty"`
+	PlaysA     int     `json:"plays_a,omitempty"`
+	PlaysB     int     `json:"plays_b,omitempty"`
+	ShareA     float64 `json:"share_a,omitempty"`
+	ShareB     float64 `json:"share_b,omitempty"`
+}
+
+type SharedTrackDTO struct {
+	TrackName  string  `json:"track_name"`
+	ArtistName string  `json:"artist_name"`
+	PlaysA     int     `json:"plays_a,omitempty"`
+	PlaysB     int     `json:"plays_b,omitempty"`
+	MinutesA   float64 `json:"minutes_a,omitempty"`
+	MinutesB   float64 `json:"minutes_b,omitempty"`
+}
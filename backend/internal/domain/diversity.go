@@ -0,0 +1,32 @@
+package domain
+
+// Métricas de diversidad sobre la distribución de reproducciones (artistas o canciones)
+type DiversityMetricsDTO struct {
+	UniqueItems       int     `json:"unique_items"`
+	Entropy           float64 `json:"entropy"`            // Shannon (nats)
+	NormalizedEntropy float64 `json:"normalized_entropy"` // 0 = todo en un item, 1 = reparto uniforme
+	Gini              float64 `json:"gini"`               // 0 = reparto uniforme, 1 = concentración total
+	Top1Share         float64 `json:"top_1_share"`        // Proporción de reproducciones del top 1
+	Top10Share        float64 `json:"top_10_share"`
+	Top100Share       float64 `json:"top_100_share"`
+}
+
+type MonthlyDiversityDTO struct {
+	YearMonth string              `json:"year_month"` // YYYY-MM
+	Artists   DiversityMetricsDTO `json:"artists"`
+	Tracks    DiversityMetricsDTO `json:"tracks"`
+}
+
+type DiversityDTO struct {
+	Artists DiversityMetricsDTO   `json:"artists"`
+	Tracks  DiversityMetricsDTO   `json:"tracks"`
+	Monthly []MonthlyDiversityDTO `json:"monthly"`
+}
+
+// Dimensión sobre la que se calcula la distribución
+type DiversityDimension string
+
+const (
+	DiversityByArtist DiversityDimension = "artist"
+	DiversityByTrack  DiversityDimension = "track"
+)
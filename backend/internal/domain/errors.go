@@ -0,0 +1,74 @@
+package domain
+
+import "fmt"
+
+// Tipo de error de la aplicación, la capa HTTP lo traduce a un status
+type ErrorKind string
+
+const (
+	ErrKindValidation       ErrorKind = "validation"
+	ErrKindNotFound         ErrorKind = "not_found"
+	ErrKindInternal         ErrorKind = "internal"
+	ErrKindTimeout          ErrorKind = "timeout"
+	ErrKindUnauthorized     ErrorKind = "unauthorized"
+	ErrKindForbidden        ErrorKind = "forbidden"
+	ErrKindMethodNotAllowed ErrorKind = "method_not_allowed"
+)
+
+// AppError transporta un mensaje apto para el cliente. Err guarda la causa original,
+// que solo se registra en el log y nunca se expone en la respuesta
+type AppError struct {
+	Kind    ErrorKind
+	Message string
+	Fields  []FieldError // Detalle por parámetro en errores de validación
+	Err     error
+}
+
+// FieldError describe un parámetro inválido y el motivo
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func NewValidationError(format string, args ...interface{}) *AppError {
+	return &AppError{Kind: ErrKindValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewFieldsValidationError agrupa todos los parámetros inválidos en un único error
+func NewFieldsValidationError(fields []FieldError) *AppError {
+	return &AppError{Kind: ErrKindValidation, Message: "Parámetros inválidos", Fields: fields}
+}
+
+func NewNotFoundError(format string, args ...interface{}) *AppError {
+	return &AppError{Kind: ErrKindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewInternalError(err error, format string, args ...interface{}) *AppError {
+	return &AppError{Kind: ErrKindInternal, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// NewUnauthorizedError indica credenciales ausentes o inválidas (401)
+func NewUnauthorizedError(format string, args ...interface{}) *AppError {
+	return &AppError{Kind: ErrKindUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewForbiddenError indica credenciales válidas sin permiso para la ruta (403)
+func NewForbiddenError(format string, args ...interface{}) *AppError {
+	return &AppError{Kind: ErrKindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewMethodNotAllowedError indica una ruta existente pedida con otro método (405)
+func NewMethodNotAllowedError(format string, args ...interface{}) *AppError {
+	return &AppError{Kind: ErrKindMethodNotAllowed, Message: fmt.Sprintf(format, args...)}
+}
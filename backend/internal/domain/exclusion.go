@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tipo de entidad excluida de las estadísticas
+type ExclusionKind string
+
+const (
+	ExcludeArtist ExclusionKind = "artist"
+	ExcludeTrack  ExclusionKind = "track"
+	ExcludeURI    ExclusionKind = "uri"
+)
+
+// Entidad excluida de todas las estadísticas, rankings y wrappeds (salvo include_excluded=true).
+// Artistas y canciones se comparan por nombre sin distinguir mayúsculas, las URIs de forma exacta
+type ExcludedEntity struct {
+	ID         int           `json:"id"`
+	Kind       ExclusionKind `json:"kind"`
+	Value      string        `json:"value"`
+	ArtistName string        `json:"artist_name,omitempty"` // Solo canciones: acota la exclusión a ese artista
+	Reason     string        `json:"reason,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type ExcludedEntityInput struct {
+	Kind       ExclusionKind `json:"kind"`
+	Value      string        `json:"value"`
+	ArtistName string        `json:"artist_name"`
+	Reason     string        `json:"reason"`
+}
+
+func (e *ExcludedEntityInput) Clean() {
+	e.Kind = ExclusionKind(strings.ToLower(strings.TrimSpace(string(e.Kind))))
+	e.Value = strings.TrimSpace(e.Value)
+	e.ArtistName = strings.TrimSpace(e.ArtistName)
+	e.Reason = strings.TrimSpace(e.Reason)
+}
+
+func (e *ExcludedEntityInput) Validate() []FieldError {
+	var errs []FieldError
+	switch e.Kind {
+	case ExcludeArtist, ExcludeTrack, ExcludeURI:
+	default:
+		errs = append(errs, FieldError{Field: "kind", Reason: "debe ser artist, track o uri"})
+	}
+	if e.Value == "" {
+		errs = append(errs, FieldError{Field: "value", Reason: "es obligatorio"})
+	}
+	if e.ArtistName != "" && e.Kind != ExcludeTrack {
+		errs = append(errs, FieldError{Field: "artist_name", Reason: fmt.Sprintf("solo aplica a exclusiones de tipo %s", ExcludeTrack)})
+	}
+	if e.Kind == ExcludeURI && !strings.HasPrefix(e.Value, "spotify:") {
+		errs = append(errs, FieldError{Field: "value", Reason: "debe ser una URI de Spotify (spotify:track:...)"})
+	}
+	return errs
+}
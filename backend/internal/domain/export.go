@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+type noQueryTimeoutCtxKey struct{}
+
+// WithoutQueryTimeout marca las consultas de la petición como exentas del statement_timeout:
+// una exportación completa puede tardar más que cualquier respuesta JSON
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noQueryTimeoutCtxKey{}, true)
+}
+
+func QueryTimeoutDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noQueryTimeoutCtxKey{}).(bool)
+	return disabled
+}
+
+// Conjunto de datos exportable como CSV, NDJSON o XLSX
+type ExportDataset string
+
+const (
+	ExportTopArtists ExportDataset = "top_artists"
+	ExportTopSongs   ExportDataset = "top_songs"
+	ExportTopAlbums  ExportDataset = "top_albums"
+	ExportHabitsTime ExportDataset = "habits_time"
+	ExportHabitsDow  ExportDataset = "habits_dow"
+	ExportEvolution  ExportDataset = "evolution"
+	ExportYearly     ExportDataset = "yearly"
+	ExportPlays      ExportDataset = "plays"
+)
+
+var rankingMetricsHeader = []string{"minutes_played", "times_played", "distinct_days", "first_played", "last_played"}
+
+// Encabezados de columnas, en el mismo orden que ExportValues de cada DTO
+var exportHeaders = map[ExportDataset][]string{
+	ExportTopArtists: append([]string{"ranking", "artist_name"}, rankingMetricsHeader...),
+	ExportTopSongs:   append([]string{"ranking", "track_name", "artist_name"}, rankingMetricsHeader...),
+	ExportTopAlbums:  append([]string{"ranking", "album_name", "artist_name"}, rankingMetricsHeader...),
+	ExportHabitsTime: {"label", "count"},
+	ExportHabitsDow:  {"num_day", "count"},
+	ExportEvolution:  {"year", "month", "year_month", "hours_monthly", "minutes_monthly"},
+	ExportYearly:     {"year", "total_hours", "total_minutes", "total_songs"},
+	ExportPlays: {
+		"id", "ts", "platform", "ms_played", "conn_country", "track_name", "artist_name", "album_name",
+		"spotify_uri", "shuffle", "reason_start", "offline", "offline_timestamp", "incognito_mode",
+	},
+}
+
+func (d ExportDataset) Header() []string {
+	return exportHeaders[d]
+}
+
+// ExportRow es una fila tabular. Los valores son string, int, float64, booleanos o fechas
+// (punteros para los nullables) para que cada formato decida cómo escribirlos (ej. celdas numéricas en XLSX)
+type ExportRow interface {
+	ExportValues() []interface{}
+}
+
+func (d ArtistRankingDTO) ExportValues() []interface{} {
+	return append([]interface{}{d.Ranking, d.ArtistName}, d.RankingMetrics.exportValues()...)
+}
+
+func (d SongRankingDTO) ExportValues() []interface{} {
+	return append([]interface{}{d.Ranking, d.TrackName, d.ArtistName}, d.RankingMetrics.exportValues()...)
+}
+
+func (d AlbumRankingDTO) ExportValues() []interface{} {
+	return append([]interface{}{d.Ranking, d.AlbumName, d.ArtistName}, d.RankingMetrics.exportValues()...)
+}
+
+// HabitTimeDTO trae Label (bloque horario) o NumDay (día de la semana), nunca ambos
+func (d HabitTimeDTO) ExportValues() []interface{} {
+	if d.NumDay != nil {
+		return []interface{}{*d.NumDay, d.Count}
+	}
+	return []interface{}{d.Label, d.Count}
+}
+
+func (d HistoryEvolutionDTO) ExportValues() []interface{} {
+	return []interface{}{d.Year, d.Month, d.YearMonth, d.HoursMonthly, d.MinutesMonthly}
+}
+
+func (d YearlyStatsDTO) ExportValues() []interface{} {
+	return []interface{}{d.Year, d.TotalHours, d.TotalMinutes, d.TotalSongs}
+}
+
+// FormatExportValue convierte un valor de ExportValues a texto
+func FormatExportValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case *bool:
+		if val != nil {
+			return strconv.FormatBool(*val)
+		}
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05")
+	case *time.Time:
+		if val != nil {
+			return val.Format("2006-01-02 15:04:05")
+		}
+	}
+	return ""
+}
@@ -1,17 +1,17 @@
 package domain
 
-type Pagination struct {
-	Total      int         `json:"total"`
-	Page       int         `json:"page"`
-	Limit      int         `json:"limit"`
-	TotalPages int         `json:"total_pages"`
-	Data       interface{} `json:"data"` // Aquí irán tus []DTOs
+type Pagination[T any] struct {
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	TotalPages int `json:"total_pages"`
+	Data       []T `json:"data"`
 }
 
 // NewPagination asegura que Data nunca sea nil
-func NewPagination(data interface{}, total, page, limit int) Pagination {
+func NewPagination[T any](data []T, total, page, limit int) Pagination[T] {
 	if data == nil {
-		data = []interface{}{} // Garantiza [] en el JSON
+		data = []T{} // Garantiza [] en el JSON
 	}
 
 	totalPages := 0
@@ -19,7 +19,7 @@ func NewPagination(data interface{}, total, page, limit int) Pagination {
 		totalPages = (total + limit - 1) / limit
 	}
 
-	return Pagination{
+	return Pagination[T]{
 		Total:      total,
 		Page:       page,
 		Limit:      limit,
@@ -27,3 +27,11 @@ func NewPagination(data interface{}, total, page, limit int) Pagination {
 		Data:       data,
 	}
 }
+
+// CursorPagination pagina por cursor en vez de número de página. NextCursor se omite en la última página
+type CursorPagination[T any] struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Data       []T    `json:"data"`
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "strings"
+
+// Familia de dispositivo a partir del texto libre de platform
+type DeviceFamily string
+
+const (
+	DeviceAndroid      DeviceFamily = "android"
+	DeviceIOS          DeviceFamily = "ios"
+	DeviceWindows      DeviceFamily = "windows"
+	DeviceMacOS        DeviceFamily = "macos"
+	DeviceLinux        DeviceFamily = "linux"
+	DeviceWeb          DeviceFamily = "web"
+	DeviceSmartSpeaker DeviceFamily = "smart_speaker"
+	DeviceCar          DeviceFamily = "car"
+	DeviceTV           DeviceFamily = "tv"
+	DeviceConsole      DeviceFamily = "console"
+	DeviceOther        DeviceFamily = "other"
+)
+
+type PlatformRule struct {
+	Family   DeviceFamily
+	Patterns []string // Subcadenas en minúsculas
+}
+
+// PlatformRules se evalúan en orden, gana la primera coincidencia.
+// Los casos específicos (web player, partners) van antes que el sistema operativo,
+// por ejemplo "web_player windows 10" es web, "Partner android_auto" es auto y
+// "Partner android_tv" es tv. Los patrones no pueden contener comillas: se copian al SQL
+var PlatformRules = []PlatformRule{
+	{DeviceWeb, []string{"web_player", "webplayer", "web player"}},
+	{DeviceCar, []string{"android_auto", "android auto", "carplay", "car thing", "car_thing", "tesla", "automotive"}},
+	{DeviceTV, []string{"android_tv", "google_tv", "fire_tv", "firetv", "apple_tv", "appletv", "tvos", "webos_tv", "tizen", "roku", "smart_tv"}},
+	{DeviceConsole, []string{"playstation", "ps4", "ps5", "xbox", "nintendo"}},
+	{DeviceSmartSpeaker, []string{"sonos", "amazon_echo", "alexa", "google_home", "cast_audio", "homepod", "bose", "speaker"}},
+	{DeviceAndroid, []string{"android"}},
+	{DeviceIOS, []string{"ios", "iphone", "ipad"}},
+	{DeviceWindows, []string{"windows"}},
+	{DeviceMacOS, []string{"os x", "osx", "macos", "mac os"}},
+	{DeviceLinux, []string{"linux"}},
+}
+
+// NormalizePlatform clasifica un platform crudo del export en su familia
+func NormalizePlatform(raw string) DeviceFamily {
+	p := strings.ToLower(raw)
+	for _, rule := range PlatformRules {
+		for _, pattern := range rule.Patterns {
+			if strings.Contains(p, pattern) {
+				return rule.Family
+			}
+		}
+	}
+	return DeviceOther
+}
+
+// IsValid indica si la familia es una de las conocidas (sin distinguir mayúsculas)
+func (d DeviceFamily) IsValid() bool {
+	family := DeviceFamily(strings.ToLower(string(d)))
+	if family == DeviceOther {
+		return true
+	}
+	for _, rule := range PlatformRules {
+		if rule.Family == family {
+			return true
+		}
+	}
+	return false
+}
+
+// Consumo por familia de dispositivo
+type PlatformStatsDTO struct {
+	Family        DeviceFamily `json:"family"`
+	Plays         int          `json:"plays"`
+	MinutesPlayed float64      `json:"minutes_played"`
+	Share         float64      `json:"share"` // Proporción de minutos sobre el total filtrado
+}
+
+// Consumo mensual por familia (gráfico de líneas)
+type PlatformEvolutionDTO struct {
+	YearMonth     string       `json:"year_month"` // YYYY-MM
+	Family        DeviceFamily `json:"family"`
+	Plays         int          `json:"plays"`
+	MinutesPlayed float64      `json:"minutes_played"`
+}
+
+type PlatformAnalyticsDTO struct {
+	Families  []PlatformStatsDTO     `json:"families"`
+	Evolution []PlatformEvolutionDTO `json:"evolution"`
+}
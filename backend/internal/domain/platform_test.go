@@ -0,0 +1,53 @@
+package domain
+
+import "testing"
+
+// Valores reales de platform en los exports de Spotify
+var platformSamples = map[string]DeviceFamily{
+	"Android OS 12 API 31 (Google, Pixel 6)":         DeviceAndroid,
+	"Android-tablet OS 11 API 30 (samsung, SM-T500)": DeviceAndroid,
+	"Partner android_auto Hyundai":                   DeviceCar,
+	"Android OS 10 API 29 (Android Auto)":            DeviceCar,
+	"Partner tesla model_3":                          DeviceCar,
+	"iOS 16.1 (iPhone14,2)":                          DeviceIOS,
+	"iOS 15.4 (iPad13,1)":                            DeviceIOS,
+	"web_player windows 10;chrome 108.0.0.0;desktop": DeviceWeb,
+	"web_player osx 10.15.7;safari 16.1;desktop":     DeviceWeb,
+	"web_player linux ;firefox 107.0;desktop":        DeviceWeb,
+	"WebPlayer (websocket RFC6455)":                  DeviceWeb,
+	"Windows 10 (10.0.19044; x64; AppX)":             DeviceWindows,
+	"OS X 12.6.1 [arm 2]":                            DeviceMacOS,
+	"macOS 13.0 (Macintosh)":                         DeviceMacOS,
+	"Linux [x86-64 0]":                               DeviceLinux,
+	"Partner sonos_amlogic_a113 Sonos Era 100":       DeviceSmartSpeaker,
+	"Partner amazon_echo Amazon Echo Dot":            DeviceSmartSpeaker,
+	"Partner google cast_audio":                      DeviceSmartSpeaker,
+	"Partner android_tv Sony BRAVIA 4K":              DeviceTV,
+	"Partner webos_tv LG OLED":                       DeviceTV,
+	"Partner tizen Samsung Smart TV":                 DeviceTV,
+	"tvOS 16.1 (AppleTV11,1)":                        DeviceTV,
+	"Partner ps5 Sony PlayStation 5":                 DeviceConsole,
+	"Xbox One (10.0.22621.1848)":                     DeviceConsole,
+	"not_applicable":                                 DeviceOther,
+	"Partner unknown_device":                         DeviceOther,
+	"":                                               DeviceOther,
+}
+
+func TestNormalizePlatform(t *testing.T) {
+	for raw, want := range platformSamples {
+		if got := NormalizePlatform(raw); got != want {
+			t.Errorf("NormalizePlatform(%q) = %s, se esperaba %s", raw, got, want)
+		}
+	}
+}
+
+func TestDeviceFamilyIsValid(t *testing.T) {
+	for _, family := range []DeviceFamily{DeviceAndroid, DeviceTV, "IOS", DeviceOther} {
+		if !family.IsValid() {
+			t.Errorf("%q debería ser válida", family)
+		}
+	}
+	if DeviceFamily("tablet").IsValid() {
+		t.Errorf("se aceptó una familia desconocida")
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlayCursor marca la última reproducción entregada. La paginación por (ts, id) evita
+// los OFFSET que recorren todo el historial al avanzar páginas
+type PlayCursor struct {
+	TS time.Time
+	ID int
+}
+
+// Encode genera el token opaco que recibe el cliente en next_cursor
+func (c PlayCursor) Encode() string {
+	raw := c.TS.Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodePlayCursor(token string) (PlayCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return PlayCursor{}, errors.New("cursor inválido")
+	}
+	tsPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return PlayCursor{}, errors.New("cursor inválido")
+	}
+	ts, err := time.Parse(time.RFC3339Nano, tsPart)
+	if err != nil {
+		return PlayCursor{}, errors.New("cursor inválido")
+	}
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return PlayCursor{}, errors.New("cursor inválido")
+	}
+	return PlayCursor{TS: ts, ID: id}, nil
+}
+
+// Parámetros de navegación del historial de reproducciones. El sentido lo define SpotifyFilters.Order
+type PlayPage struct {
+	After *PlayCursor // nil = desde el inicio
+	Limit int         // 0 = sin límite (solo exportaciones)
+}
+
+func (p *PlayPage) Clean() {
+	if p.Limit < 0 {
+		p.Limit = 0
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+}
+
+// Exportación de reproducciones individuales
+func (r SpotifyRecord) ExportValues() []interface{} {
+	return []interface{}{
+		r.ID, r.TS, r.Platform, r.MsPlayed, r.ConnCountry, r.TrackName, r.ArtistName, r.AlbumName,
+		r.SpotifyURI, r.Shuffle, r.ReasonStart, r.Offline, r.OfflineTimestamp, r.IncognitoMode,
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestPlayCursorRoundTrip(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Santiago")
+	cursors := []PlayCursor{
+		{TS: time.Date(2024, 3, 9, 23, 59, 59, 123456789, time.UTC), ID: 42},
+		{TS: time.Date(2019, 1, 1, 0, 0, 0, 0, loc), ID: 1},
+		{TS: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), ID: 0},
+	}
+	for _, c := range cursors {
+		got, err := DecodePlayCursor(c.Encode())
+		if err != nil {
+			t.Fatalf("DecodePlayCursor(%v): %v", c, err)
+		}
+		if !got.TS.Equal(c.TS) || got.ID != c.ID {
+			t.Errorf("ida y vuelta de %v entregó %v", c, got)
+		}
+	}
+}
+
+func TestDecodePlayCursorRejectsInvalid(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tokens := map[string]string{
+		"vacío":             "",
+		"no es base64":      "%%%",
+		"base64 estándar":   base64.StdEncoding.EncodeToString([]byte("2024-01-01T00:00:00Z|1")),
+		"sin separador":     enc("2024-01-01T00:00:00Z"),
+		"fecha inválida":    enc("ayer|1"),
+		"id no numérico":    enc("2024-01-01T00:00:00Z|abc"),
+		"id vacío":          enc("2024-01-01T00:00:00Z|"),
+		"campos invertidos": enc("1|2024-01-01T00:00:00Z"),
+		"cursor editado":    PlayCursor{TS: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: 7}.Encode()[1:],
+	}
+	for name, token := range tokens {
+		if c, err := DecodePlayCursor(token); err == nil {
+			t.Errorf("%s: %q se aceptó como %v", name, token, c)
+		}
+	}
+}
+
+func TestPlayPageClean(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{-1, 0},
+		{0, 0},
+		{50, 50},
+		{MaxLimit + 1, MaxLimit},
+	}
+	for _, tt := range tests {
+		p := PlayPage{Limit: tt.in}
+		p.Clean()
+		if p.Limit != tt.want {
+			t.Errorf("Clean(%d) = %d, se esperaba %d", tt.in, p.Limit, tt.want)
+		}
+	}
+}
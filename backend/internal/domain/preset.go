@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Preset de filtros guardado en el servidor. Se aplica con ?preset=<name> y los
+// parámetros explícitos de la petición tienen prioridad sobre los guardados
+type FilterPreset struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Params      PresetParams `json:"params"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Cuerpo de creación y edición de presets
+type FilterPresetInput struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Params      PresetParams `json:"params"`
+}
+
+const maxPresetNameLength = 64
+
+func (p *FilterPresetInput) Clean() {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Description = strings.TrimSpace(p.Description)
+	if p.Params == nil {
+		p.Params = PresetParams{}
+	}
+}
+
+func (p *FilterPresetInput) Validate() []FieldError {
+	var errs []FieldError
+	if p.Name == "" {
+		errs = append(errs, FieldError{Field: "name", Reason: "es obligatorio"})
+	}
+	if utf8.RuneCountInString(p.Name) > maxPresetNameLength {
+		errs = append(errs, FieldError{Field: "name", Reason: fmt.Sprintf("no puede superar %d caracteres", maxPresetNameLength)})
+	}
+	return errs
+}
+
+// PresetParams guarda los parámetros con los nombres de la query. En JSON acepta valores
+// sueltos o arreglos ("artist": "A" o "artist": ["A", "B"]) de texto, número o booleano
+type PresetParams map[string][]string
+
+func (p *PresetParams) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	res := PresetParams{}
+	for key, value := range raw {
+		var list []interface{}
+		if err := json.Unmarshal(value, &list); err != nil {
+			var single interface{}
+			if err := json.Unmarshal(value, &single); err != nil {
+				return err
+			}
+			list = []interface{}{single}
+		}
+		for _, v := range list {
+			switch val := v.(type) {
+			case string:
+				res[key] = append(res[key], val)
+			case float64, bool:
+				res[key] = append(res[key], fmt.Sprint(val))
+			case nil:
+			default:
+				return fmt.Errorf("el parámetro %q debe ser texto, número, booleano o un arreglo de ellos", key)
+			}
+		}
+	}
+	*p = res
+	return nil
+}
+
+// MarshalJSON escribe los parámetros de un solo valor sin arreglo, como se escribirían en la query
+func (p PresetParams) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(p))
+	for key, values := range p {
+		if len(values) == 1 {
+			out[key] = values[0]
+		} else {
+			out[key] = values
+		}
+	}
+	return json.Marshal(out)
+}
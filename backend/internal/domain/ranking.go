@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// Criterio con el que se ordenan y numeran los rankings (sort_by)
+type RankingSort string
+
+const (
+	SortByPlays        RankingSort = "plays" // Por defecto
+	SortByMinutes      RankingSort = "minutes"
+	SortByDistinctDays RankingSort = "distinct_days" // Días distintos con al menos una reproducción
+	SortByFirstPlayed  RankingSort = "first_played"
+	SortByLastPlayed   RankingSort = "last_played"
+)
+
+var RankingSorts = []RankingSort{SortByPlays, SortByMinutes, SortByDistinctDays, SortByFirstPlayed, SortByLastPlayed}
+
+func (s RankingSort) IsValid() bool {
+	for _, valid := range RankingSorts {
+		if s == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Dirección de orden (order), compartida por los rankings y el historial de reproducciones
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc" // Por defecto: más reproducido o más reciente primero
+	SortAsc  SortOrder = "asc"
+)
+
+func (o SortOrder) IsValid() bool {
+	return o == SortDesc || o == SortAsc
+}
+
+// Métricas comunes a los rankings de artistas, canciones y álbumes
+type RankingMetrics struct {
+	MinutesPlayed float64   `json:"minutes_played"`
+	TimesPlayed   int       `json:"times_played"`
+	DistinctDays  int       `json:"distinct_days"`
+	FirstPlayed   time.Time `json:"first_played"`
+	LastPlayed    time.Time `json:"last_played"`
+}
+
+func (m RankingMetrics) exportValues() []interface{} {
+	return []interface{}{m.MinutesPlayed, m.TimesPlayed, m.DistinctDays, m.FirstPlayed, m.LastPlayed}
+}
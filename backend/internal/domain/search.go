@@ -0,0 +1,29 @@
+package domain
+
+// Largo mínimo del término de búsqueda, con menos letras los trigramas no discriminan
+const MinSearchLength = 2
+
+// Grupo de resultados de la búsqueda aproximada
+type SearchKind string
+
+const (
+	SearchArtists SearchKind = "artists"
+	SearchAlbums  SearchKind = "albums"
+	SearchTracks  SearchKind = "tracks"
+)
+
+// Coincidencia de la búsqueda. Score es la similitud por trigramas (0-1)
+type SearchHitDTO struct {
+	Name          string  `json:"name"`
+	ArtistName    string  `json:"artist_name,omitempty"` // Vacío en el grupo de artistas
+	Plays         int     `json:"plays"`
+	MinutesPlayed float64 `json:"minutes_played"`
+	Score         float64 `json:"score"`
+}
+
+type SearchResultDTO struct {
+	Query   string         `json:"query"`
+	Artists []SearchHitDTO `json:"artists"`
+	Albums  []SearchHitDTO `json:"albums"`
+	Tracks  []SearchHitDTO `json:"tracks"`
+}
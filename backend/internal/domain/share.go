@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Vigencia máxima de un enlace compartido (expires_in, en horas)
+const MaxShareExpiryHours = 24 * 365
+
+// Enlace público de solo lectura a un wrapped congelado al momento de compartir
+type ShareLink struct {
+	ID        int              `json:"id"`
+	Period    WrappedPeriodDTO `json:"period"`
+	Token     string           `json:"token,omitempty"` // Solo en la respuesta de creación, después no se puede recuperar
+	URL       string           `json:"url,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	ExpiresAt *time.Time       `json:"expires_at,omitempty"`
+	RevokedAt *time.Time       `json:"revoked_at,omitempty"`
+}
+
+// Lo que ve quien abre /share/{token}
+type SharedWrappedDTO struct {
+	Wrapped   WrappedDTO `json:"wrapped"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -1,21 +1,27 @@
 package domain
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
 
 // Entidad de Base de Datos
 type SpotifyRecord struct {
-	ID          int       `json:"id"`
-	TS          time.Time `json:"ts"`
-	Platform    string    `json:"platform"`
-	MsPlayed    int       `json:"ms_played"`
-	ConnCountry string    `json:"conn_country"`
-	TrackName   string    `json:"track_name"`
-	ArtistName  string    `json:"artist_name"`
-	AlbumName   string    `json:"album_name"`
-	SpotifyURI  string    `json:"spotify_uri"`
+	ID               int        `json:"id"`
+	TS               time.Time  `json:"ts"`
+	Platform         string     `json:"platform"`
+	MsPlayed         int        `json:"ms_played"`
+	ConnCountry      string     `json:"conn_country"`
+	TrackName        string     `json:"track_name"`
+	ArtistName       string     `json:"artist_name"`
+	AlbumName        string     `json:"album_name"`
+	SpotifyURI       string     `json:"spotify_uri"`
+	Shuffle          *bool      `json:"shuffle"`      // nil en registros importados antes de existir la columna
+	ReasonStart      string     `json:"reason_start"` // clickrow, trackdone, autoplay, fwdbtn, ...
+	Offline          *bool      `json:"offline"`
+	OfflineTimestamp *time.Time `json:"offline_timestamp"` // Momento de la reproducción sin conexión
+	IncognitoMode    *bool      `json:"incognito_mode"`    // Sesión privada
 }
 
 // DTO para Estadísticas Generales
@@ -29,24 +35,23 @@ type TotalStatsDTO struct {
 
 // DTO para Rankings
 type ArtistRankingDTO struct {
-	Ranking       int     `json:"ranking"`
-	ArtistName    string  `json:"artist_name"`
-	MinutesPlayed float64 `json:"minutes_played"`
-	TimesPlayed   int     `json:"times_played"`
+	Ranking    int    `json:"ranking"`
+	ArtistName string `json:"artist_name"`
+	RankingMetrics
 }
 
 type SongRankingDTO struct {
-	Ranking     int    `json:"ranking"`
-	TrackName   string `json:"track_name"`
-	ArtistName  string `json:"artist_name"`
-	TimesPlayed int    `json:"times_played"`
+	Ranking    int    `json:"ranking"`
+	TrackName  string `json:"track_name"`
+	ArtistName string `json:"artist_name"`
+	RankingMetrics
 }
 
 type AlbumRankingDTO struct {
-	Ranking     int    `json:"ranking"`
-	AlbumName   string `json:"album_name"`
-	ArtistName  string `json:"artist_name"`
-	TimesPlayed int    `json:"times_played"`
+	Ranking    int    `json:"ranking"`
+	AlbumName  string `json:"album_name"`
+	ArtistName string `json:"artist_name"`
+	RankingMetrics
 }
 
 type HabitTimeDTO struct {
@@ -70,29 +75,177 @@ type HistoryEvolutionDTO struct {
 	MinutesMonthly float64 `json:"minutes_monthly"`
 }
 
+// Resultado de buscar la posición de una canción o artista en el ranking.
+// Type indica cuál de las dos listas viene informada
+type RankSearchResultDTO struct {
+	Type    string             `json:"type"` // "song" o "artist"
+	Songs   []SongRankingDTO   `json:"songs,omitempty"`
+	Artists []ArtistRankingDTO `json:"artists,omitempty"`
+}
+
+// Racha de reproducciones consecutivas de una misma canción o artista
+type BingeDTO struct {
+	Ranking        int       `json:"ranking"`
+	TrackName      string    `json:"track_name,omitempty"` // Vacío en rachas de artista
+	ArtistName     string    `json:"artist_name"`
+	StartedAt      time.Time `json:"started_at"`
+	EndedAt        time.Time `json:"ended_at"`
+	Plays          int       `json:"plays"`
+	MinutesPlayed  float64   `json:"minutes_played"`
+	DistinctTracks int       `json:"distinct_tracks"`
+}
+
+// Consumo por país de conexión (conn_country, ZZ = desconocido)
+type CountryStatsDTO struct {
+	Country       string             `json:"country"`
+	Plays         int                `json:"plays"`
+	MinutesPlayed float64            `json:"minutes_played"`
+	FirstPlayed   time.Time          `json:"first_played"`
+	LastPlayed    time.Time          `json:"last_played"`
+	TopArtists    []ArtistRankingDTO `json:"top_artists"`
+}
+
+// Tramo continuo escuchando desde un mismo país (viajes)
+type CountryTripDTO struct {
+	Country       string    `json:"country"`
+	StartedAt     time.Time `json:"started_at"`
+	EndedAt       time.Time `json:"ended_at"`
+	Days          int       `json:"days"`
+	Plays         int       `json:"plays"`
+	MinutesPlayed float64   `json:"minutes_played"`
+}
+
+type CountryAnalyticsDTO struct {
+	Countries []CountryStatsDTO `json:"countries"`
+	Trips     []CountryTripDTO  `json:"trips"`
+}
+
 // Filtros de búsqueda
 type SpotifyFilters struct {
 	StartDate *time.Time
 	EndDate   *time.Time
-	Search    string // Para artista o álbum
-	Artist    string // Filtro específico
-	Track     string // Filtro específico
-	StartHour *int   // 0-23
-	EndHour   *int   // 0-23
+	Search    string         // Para artista o álbum
+	Artists   []string       // Repetible (artist=A&artist=B): basta con que calce uno
+	Tracks    []string       // Repetible, igual que Artists
+	Match     MatchMode      // Cómo se comparan Artists, Tracks y sus exclusiones
+	Platforms []DeviceFamily // Familias de dispositivo (android, ios, web, ...)
+	Countries []string       // Códigos ISO de conn_country (ZZ = desconocido)
+	StartHour *int           // 0-23. Si es mayor que EndHour el rango cruza la medianoche (22 a 3)
+	EndHour   *int           // 0-23. Cada extremo puede venir solo (desde / hasta)
+	Weekdays  []time.Weekday // Días de la semana (weekday=sat,sun). Vacío pero no nil = ningún día
 	Page      int
 	Limit     int
+	SortBy    RankingSort // Métrica de los rankings (plays, minutes, ...)
+	Order     SortOrder   // asc o desc
+
+	// Filtros negativos (exclude_artist=, ...): se descarta lo que calce con cualquiera de ellos
+	ExcludeArtists   []string
+	ExcludeTracks    []string
+	ExcludePlatforms []DeviceFamily
+	ExcludeCountries []string
+
+	IncludeExcluded  bool // Ignorar la lista de exclusión global (excluded_entities)
+	Unpaged          bool // Sin LIMIT/OFFSET, para exportaciones completas
+	ExcludeIncognito bool // Omitir sesiones privadas
+	IncognitoOnly    bool // Solo sesiones privadas (uso interno del análisis de incógnito)
 }
+
+// Modo de comparación de los filtros de artista y canción
+type MatchMode string
+
+const (
+	MatchContains MatchMode = "contains" // Por defecto, el nombre contiene el texto (sin distinguir mayúsculas)
+	MatchExact    MatchMode = "exact"    // Nombre completo, sin distinguir mayúsculas
+)
+
 type ArtistTrackFilters struct {
 	Artist string
 	Track  string
 }
 
+// Límite máximo de items por página, protege a la base de datos de limit=1000000
+const MaxLimit = 500
+
+// Validate revisa la coherencia de los filtros sin modificarlos.
+// Lo usa el modo estricto, CleanAndValidate en cambio corrige los valores silenciosamente
+func (f *SpotifyFilters) Validate() []FieldError {
+	var errs []FieldError
+	if f.StartHour != nil && (*f.StartHour < 0 || *f.StartHour > 23) {
+		errs = append(errs, FieldError{Field: "start_hour", Reason: "debe estar entre 0 y 23"})
+	}
+	if f.EndHour != nil && (*f.EndHour < 0 || *f.EndHour > 23) {
+		errs = append(errs, FieldError{Field: "end_hour", Reason: "debe estar entre 0 y 23"})
+	}
+	if f.StartDate != nil && f.EndDate != nil && f.StartDate.After(*f.EndDate) {
+		errs = append(errs, FieldError{Field: "start_date", Reason: "no puede ser posterior a end_date"})
+	}
+	if f.Limit < 0 || f.Limit > MaxLimit {
+		errs = append(errs, FieldError{Field: "limit", Reason: fmt.Sprintf("debe estar entre 1 y %d", MaxLimit)})
+	}
+	if f.Page < 0 {
+		errs = append(errs, FieldError{Field: "page", Reason: "debe ser mayor o igual a 1"})
+	}
+	for _, field := range []struct {
+		name      string
+		countries []string
+	}{{"country", f.Countries}, {"exclude_country", f.ExcludeCountries}} {
+		for _, c := range field.countries {
+			if !isCountryCode(c) {
+				errs = append(errs, FieldError{Field: field.name, Reason: fmt.Sprintf("%q no es un código ISO de 2 letras", c)})
+			}
+		}
+	}
+	for _, field := range []struct {
+		name      string
+		platforms []DeviceFamily
+	}{{"platform", f.Platforms}, {"exclude_platform", f.ExcludePlatforms}} {
+		for _, p := range field.platforms {
+			if !p.IsValid() {
+				errs = append(errs, FieldError{Field: field.name, Reason: fmt.Sprintf("familia de dispositivo desconocida: %q", p)})
+			}
+		}
+	}
+	for _, d := range f.Weekdays {
+		if d < time.Sunday || d > time.Saturday {
+			errs = append(errs, FieldError{Field: "weekday", Reason: "debe estar entre 0 (domingo) y 6 (sábado)"})
+		}
+	}
+	if f.Match != "" && f.Match != MatchContains && f.Match != MatchExact {
+		errs = append(errs, FieldError{Field: "match", Reason: "debe ser contains o exact"})
+	}
+	if f.SortBy != "" && !f.SortBy.IsValid() {
+		errs = append(errs, FieldError{Field: "sort_by", Reason: "debe ser plays, minutes, distinct_days, first_played o last_played"})
+	}
+	if f.Order != "" && !f.Order.IsValid() {
+		errs = append(errs, FieldError{Field: "order", Reason: "debe ser asc o desc"})
+	}
+	return errs
+}
+
 // Limpieza y validación de filtros
 func (f *SpotifyFilters) CleanAndValidate() {
 	// 1. Trim de strings para evitar espacios accidentales
 	f.Search = strings.TrimSpace(f.Search)
-	f.Artist = strings.TrimSpace(f.Artist)
-	f.Track = strings.TrimSpace(f.Track)
+	f.Artists = cleanList(f.Artists, strings.TrimSpace)
+	f.Tracks = cleanList(f.Tracks, strings.TrimSpace)
+	f.ExcludeArtists = cleanList(f.ExcludeArtists, strings.TrimSpace)
+	f.ExcludeTracks = cleanList(f.ExcludeTracks, strings.TrimSpace)
+	f.Platforms = cleanList(f.Platforms, normalizeFamily)
+	f.ExcludePlatforms = cleanList(f.ExcludePlatforms, normalizeFamily)
+	f.Countries = cleanList(f.Countries, normalizeCountry)
+	f.ExcludeCountries = cleanList(f.ExcludeCountries, normalizeCountry)
+	f.Match = MatchMode(strings.ToLower(strings.TrimSpace(string(f.Match))))
+	if f.Match != MatchExact {
+		f.Match = MatchContains
+	}
+	f.SortBy = RankingSort(strings.ToLower(strings.TrimSpace(string(f.SortBy))))
+	if !f.SortBy.IsValid() {
+		f.SortBy = SortByPlays
+	}
+	f.Order = SortOrder(strings.ToLower(strings.TrimSpace(string(f.Order))))
+	if !f.Order.IsValid() {
+		f.Order = SortDesc
+	}
 
 	// 2. Validación de rango de horas
 	if f.StartHour != nil {
@@ -127,6 +280,40 @@ func (f *SpotifyFilters) CleanAndValidate() {
 	if f.Limit <= 0 {
 		f.Limit = 10
 	}
+	if f.Limit > MaxLimit {
+		f.Limit = MaxLimit
+	}
+}
+
+// cleanList normaliza cada valor y descarta los vacíos
+func cleanList[T ~string](values []T, normalize func(string) string) []T {
+	var res []T
+	for _, v := range values {
+		if n := normalize(string(v)); n != "" {
+			res = append(res, T(n))
+		}
+	}
+	return res
+}
+
+func normalizeFamily(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func normalizeCountry(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+func isCountryCode(c string) bool {
+	if len(c) != 2 {
+		return false
+	}
+	for _, r := range strings.ToUpper(c) {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
 }
 
 // Offset calcula el salto para SQL
@@ -134,6 +321,36 @@ func (f *SpotifyFilters) Offset() int {
 	return (f.Page - 1) * f.Limit
 }
 
+// Parámetros de detección de rachas
+type BingeFilters struct {
+	Type          string // "track" (por defecto) o "artist"
+	MinPlays      int    // Largo mínimo de la racha, 0 = por defecto
+	MaxGapMinutes int    // Pausa máxima entre reproducciones para seguir contando la racha, 0 = por defecto
+}
+
+const (
+	DefaultBingeMinPlays      = 3
+	DefaultBingeMaxGapMinutes = 30
+	MinBingePlays             = 2       // Una sola reproducción no es una racha
+	MaxBingeGapMinutes        = 24 * 60 // Con pausas de más de un día ya no es escucha en bucle
+)
+
+// Clean completa los valores por defecto y lleva los fuera de rango al valor válido más cercano
+func (f *BingeFilters) Clean() {
+	f.Type = strings.ToLower(strings.TrimSpace(f.Type))
+	if f.Type != "artist" {
+		f.Type = "track"
+	}
+	if f.MinPlays == 0 {
+		f.MinPlays = DefaultBingeMinPlays
+	}
+	f.MinPlays = max(f.MinPlays, MinBingePlays)
+	if f.MaxGapMinutes == 0 {
+		f.MaxGapMinutes = DefaultBingeMaxGapMinutes
+	}
+	f.MaxGapMinutes = min(max(f.MaxGapMinutes, 1), MaxBingeGapMinutes)
+}
+
 func (f *ArtistTrackFilters) Clean() {
 	f.Artist = strings.TrimSpace(f.Artist)
 	f.Track = strings.TrimSpace(f.Track)
@@ -147,3 +364,46 @@ const (
 	Winter Season = "winter"
 	Spring Season = "spring"
 )
+
+// Escucha sin conexión y retraso hasta la sincronización (ts - offline_timestamp)
+type OfflineStatsDTO struct {
+	Plays              int     `json:"plays"`
+	OfflinePlays       int     `json:"offline_plays"`
+	OfflineMinutes     float64 `json:"offline_minutes"`
+	OfflineShare       float64 `json:"offline_share"`
+	AvgSyncLagHours    float64 `json:"avg_sync_lag_hours"`
+	MedianSyncLagHours float64 `json:"median_sync_lag_hours"`
+	MaxSyncLagHours    float64 `json:"max_sync_lag_hours"`
+}
+
+// Escucha en sesiones privadas
+type IncognitoStatsDTO struct {
+	Plays            int                `json:"plays"`
+	IncognitoPlays   int                `json:"incognito_plays"`
+	IncognitoMinutes float64            `json:"incognito_minutes"`
+	IncognitoShare   float64            `json:"incognito_share"`
+	TopArtists       []ArtistRankingDTO `json:"top_artists"` // Más escuchados en modo privado
+}
+
+// Desglose de cómo empezaron las reproducciones (shuffle y reason_start)
+type SourceBreakdownDTO struct {
+	Plays          int     `json:"plays"`
+	ShufflePlays   int     `json:"shuffle_plays"`
+	ShuffleShare   float64 `json:"shuffle_share"`
+	ClickShare     float64 `json:"click_share"`     // clickrow / playbtn: elegida por el usuario
+	AutoplayShare  float64 `json:"autoplay_share"`  // Servida por autoplay
+	TrackDoneShare float64 `json:"trackdone_share"` // Continuación al terminar la anterior
+	OtherShare     float64 `json:"other_share"`     // fwdbtn, backbtn, appload, remote, ...
+	UnknownShare   float64 `json:"unknown_share"`   // Registros sin reason_start
+}
+
+type ArtistSourceBreakdownDTO struct {
+	Ranking    int    `json:"ranking"`
+	ArtistName string `json:"artist_name"`
+	SourceBreakdownDTO
+}
+
+type ListeningSourcesDTO struct {
+	Overall SourceBreakdownDTO                   `json:"overall"`
+	Artists Pagination[ArtistSourceBreakdownDTO] `json:"artists"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestBingeFiltersClean(t *testing.T) {
+	tests := []struct {
+		in   BingeFilters
+		want BingeFilters
+	}{
+		{BingeFilters{}, BingeFilters{Type: "track", MinPlays: 3, MaxGapMinutes: 30}},
+		{BingeFilters{Type: " Artist ", MinPlays: 5, MaxGapMinutes: 10}, BingeFilters{Type: "artist", MinPlays: 5, MaxGapMinutes: 10}},
+		{BingeFilters{Type: "album"}, BingeFilters{Type: "track", MinPlays: 3, MaxGapMinutes: 30}},
+		// Fuera de rango se acota al valor válido más cercano, no al por defecto
+		{BingeFilters{MinPlays: 1, MaxGapMinutes: -5}, BingeFilters{Type: "track", MinPlays: 2, MaxGapMinutes: 1}},
+		{BingeFilters{MinPlays: -3, MaxGapMinutes: 100000}, BingeFilters{Type: "track", MinPlays: 2, MaxGapMinutes: MaxBingeGapMinutes}},
+		{BingeFilters{MinPlays: 2, MaxGapMinutes: MaxBingeGapMinutes}, BingeFilters{Type: "track", MinPlays: 2, MaxGapMinutes: MaxBingeGapMinutes}},
+	}
+	for _, tt := range tests {
+		got := tt.in
+		got.Clean()
+		if got != tt.want {
+			t.Errorf("Clean(%+v) = %+v, se esperaba %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Usuario dueño de un historial. Los datos anteriores al soporte multiusuario pertenecen a DefaultUserID
+type User struct {
+	ID          int       `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Usuario creado por la migración 008_users.sql
+const (
+	DefaultUserID   = 1
+	DefaultUsername = "default"
+)
+
+// Rol de acceso a la API. admin incluye todo lo que puede hacer reader
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleAdmin  Role = "admin"
+)
+
+func (r Role) IsValid() bool {
+	return r == RoleReader || r == RoleAdmin
+}
+
+// Allows indica si el rol cumple con el requerido por una ruta
+func (r Role) Allows(required Role) bool {
+	return r == RoleAdmin || r == required
+}
+
+// Identity es quién hace la petición: el usuario cuyos datos se consultan y su rol
+type Identity struct {
+	UserID   int
+	Username string
+	Role     Role
+}
+
+type identityCtxKey struct{}
+
+// WithIdentity asocia la identidad autenticada al contexto de la petición
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityCtxKey{}, id)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityCtxKey{}).(Identity)
+	return id, ok
+}
+
+// UserIDFromContext retorna el usuario de la petición, o 0 si no hay ninguno.
+// Ningún registro tiene user_id 0, así una consulta sin usuario no retorna datos de nadie
+func UserIDFromContext(ctx context.Context) int {
+	id, _ := IdentityFromContext(ctx)
+	return id.UserID
+}
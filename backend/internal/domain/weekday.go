@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nombres aceptados en weekday= (inglés y español, abreviados o completos) además de 0-6 (domingo = 0)
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "dom": time.Sunday, "domingo": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "lun": time.Monday, "lunes": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "mar": time.Tuesday, "martes": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "mie": time.Wednesday, "miercoles": time.Wednesday, "miércoles": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "jue": time.Thursday, "jueves": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "vie": time.Friday, "viernes": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "sab": time.Saturday, "sabado": time.Saturday, "sábado": time.Saturday,
+}
+
+// ParseWeekday interpreta un día de la semana por nombre o número
+func ParseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if d, ok := weekdayNames[s]; ok {
+		return d, true
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 6 {
+		return time.Weekday(n), true
+	}
+	return 0, false
+}
+
+// Tipo de día para split=day_type
+type DayType string
+
+const (
+	DayTypeWeekday DayType = "weekday"
+	DayTypeWeekend DayType = "weekend"
+)
+
+var (
+	WeekendDays = []time.Weekday{time.Saturday, time.Sunday}
+	WorkingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+)
+
+// Resultado de un endpoint separado entre días hábiles y fin de semana (split=day_type)
+type DayTypeSplit[T any] struct {
+	Weekday T `json:"weekday"`
+	Weekend T `json:"weekend"`
+}
+
+// WithWeekdays retorna una copia de los filtros limitada a days. Si ya había un filtro
+// weekday=, se conserva solo la intersección (puede quedar vacía y entonces no calza nada)
+func (f SpotifyFilters) WithWeekdays(days []time.Weekday) SpotifyFilters {
+	if f.Weekdays == nil {
+		f.Weekdays = days
+		return f
+	}
+	var both []time.Weekday
+	for _, d := range f.Weekdays {
+		for _, allowed := range days {
+			if d == allowed {
+				both = append(both, d)
+			}
+		}
+	}
+	if both == nil {
+		both = []time.Weekday{} // Intersección vacía: no es lo mismo que sin filtro
+	}
+	f.Weekdays = both
+	return f
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Weekday
+		ok   bool
+	}{
+		{"sun", time.Sunday, true},
+		{"Saturday", time.Saturday, true},
+		{" LUN ", time.Monday, true},
+		{"miércoles", time.Wednesday, true},
+		{"miercoles", time.Wednesday, true},
+		{"0", time.Sunday, true},
+		{"6", time.Saturday, true},
+		{"7", 0, false},
+		{"-1", 0, false},
+		{"", 0, false},
+		{"weekend", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseWeekday(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseWeekday(%q) = %v, %v; se esperaba %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWithWeekdays(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter []time.Weekday
+		days   []time.Weekday
+		want   []time.Weekday
+	}{
+		{"sin filtro previo", nil, WeekendDays, WeekendDays},
+		{"intersección parcial", []time.Weekday{time.Friday, time.Saturday}, WeekendDays, []time.Weekday{time.Saturday}},
+		{"filtro contenido", []time.Weekday{time.Monday}, WorkingDays, []time.Weekday{time.Monday}},
+		{"intersección vacía", []time.Weekday{time.Monday}, WeekendDays, []time.Weekday{}},
+		{"filtro que ya no calzaba", []time.Weekday{}, WeekendDays, []time.Weekday{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpotifyFilters{Weekdays: tt.filter}.WithWeekdays(tt.days).Weekdays
+			// nil es "sin filtro" y vacío es "ningún día": DeepEqual distingue ambos
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithWeekdays = %#v, se esperaba %#v", got, tt.want)
+			}
+		})
+	}
+}
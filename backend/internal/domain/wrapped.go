@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Tipo de periodo de un wrapped
+type WrappedType string
+
+const (
+	WrappedYear   WrappedType = "year"
+	WrappedMonth  WrappedType = "month"
+	WrappedSeason WrappedType = "season"
+)
+
+type WrappedPeriodDTO struct {
+	Type      WrappedType `json:"type"`
+	Year      int         `json:"year"`
+	Month     int         `json:"month,omitempty"`
+	Season    Season      `json:"season,omitempty"`
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+}
+
+// Resumen completo de un periodo (contrato de /api/v2/spotify/wrapped).
+// /api/v1 sigue respondiendo solo TopSongs
+type WrappedDTO struct {
+	Period     WrappedPeriodDTO   `json:"period"`
+	Stats      TotalStatsDTO      `json:"stats"`
+	TopSongs   []SongRankingDTO   `json:"top_songs"`
+	TopArtists []ArtistRankingDTO `json:"top_artists"`
+	TopAlbums  []AlbumRankingDTO  `json:"top_albums"`
+	TimeOfDay  []HabitTimeDTO     `json:"time_of_day"`
+}
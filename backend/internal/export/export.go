@@ -0,0 +1,140 @@
+// Package export escribe listados tabulares como CSV, NDJSON o XLSX
+// fila a fila, sin acumular el resultado completo en memoria
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+var contentTypes = map[Format]string{
+	FormatJSON:   "application/json",
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Tipos MIME aceptados en el header Accept
+var acceptTypes = map[string]Format{
+	"application/json":     FormatJSON,
+	"text/csv":             FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+	"application/ndjson":   FormatNDJSON,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": FormatXLSX,
+}
+
+// Formats lista los formatos soportados (documentación OpenAPI)
+func Formats() []string {
+	return []string{string(FormatJSON), string(FormatCSV), string(FormatNDJSON), string(FormatXLSX)}
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Negotiate elige el formato de respuesta. El parámetro format= tiene prioridad sobre Accept;
+// un format= desconocido es un error, un Accept sin coincidencias cae en JSON
+func Negotiate(r *http.Request) (Format, error) {
+	if v := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); v != "" {
+		f := Format(v)
+		if _, ok := contentTypes[f]; !ok {
+			return "", fmt.Errorf("formato desconocido %q. Use: %s", v, strings.Join(Formats(), ", "))
+		}
+		return f, nil
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if f, ok := acceptTypes[mediaType]; ok {
+			return f, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// Writer escribe filas en el formato elegido. Close debe llamarse al terminar
+type Writer interface {
+	WriteRow(row domain.ExportRow) error
+	Close() error
+}
+
+// NewWriter crea el escritor para format. header son los nombres de columna (CSV y XLSX)
+func NewWriter(format Format, w io.Writer, header []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, header)
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, header)
+	}
+	return nil, fmt.Errorf("formato %q no soporta exportación por filas", format)
+}
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) WriteRow(row domain.ExportRow) error {
+	values := row.ExportValues()
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = domain.FormatExportValue(v)
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	// Vacía el buffer cada cierto número de filas para que el cliente reciba datos de inmediato
+	c.rows++
+	if c.rows%500 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter emite cada fila como el mismo objeto JSON que devuelve el listado paginado
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) WriteRow(row domain.ExportRow) error {
+	return n.enc.Encode(row)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
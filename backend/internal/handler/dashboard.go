@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/auth"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/card"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+//go:embed templates/dashboard.html templates/login.html
+var templatesFS embed.FS
+
+// DashboardHandler sirve el dashboard HTML. Usa los mismos métodos de servicio y el mismo
+// parser de filtros que la API JSON (/api/v1), así ambos muestran siempre las mismas cifras
+type DashboardHandler struct {
+	api   *SpotifyHandler
+	authn *auth.Authenticator // nil con la autenticación deshabilitada
+	tmpl  *template.Template
+}
+
+func NewDashboardHandler(api *SpotifyHandler, authn *auth.Authenticator) *DashboardHandler {
+	tmpl := template.Must(template.New("dashboard.html").Funcs(template.FuncMap{
+		"thousands": func(v float64) string { return card.FormatThousands(int(math.Round(v))) },
+		"decimal":   func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) },
+	}).ParseFS(templatesFS, "templates/dashboard.html", "templates/login.html"))
+	return &DashboardHandler{api: api, authn: authn, tmpl: tmpl}
+}
+
+// Dimensiones del viewBox de los gráficos; el CSS los escala al ancho disponible
+const (
+	barChartW, barChartH   = 420.0, 220.0
+	lineChartW, lineChartH = 900.0, 260.0
+	chartLabelSpace        = 28.0 // Espacio inferior para las etiquetas del eje X
+)
+
+// chartBar es una barra ya posicionada en el viewBox
+type chartBar struct {
+	Label         string
+	Value         int
+	X, Y, W, H    float64
+	LabelX, TextY float64
+}
+
+// lineChart es la evolución mensual: el área (Points) y las etiquetas de cada año
+type lineChart struct {
+	Points string
+	Area   string
+	Years  []chartTick
+	Max    float64
+}
+
+type chartTick struct {
+	Label string
+	X     float64
+}
+
+type dashboardView struct {
+	Session            bool // Se ingresó con el formulario, se muestra el botón para salir
+	StartDate, EndDate string
+	ExcludeIncognito   bool
+	Stats              domain.TotalStatsDTO
+	TopArtists         []domain.ArtistRankingDTO
+	TopSongs           []domain.SongRankingDTO
+	TopAlbums          []domain.AlbumRankingDTO
+	TimeOfDay          []chartBar
+	DayOfWeek          []chartBar
+	Evolution          lineChart
+	BarChartW          float64
+	BarChartH          float64
+	LineChartW         float64
+	LineChartH         float64
+}
+
+// Orden cronológico de los bloques horarios (la consulta los entrega por cantidad)
+var timeOfDayOrder = []string{"Madrugada", "Mañana", "Tarde", "Noche"}
+
+var dayNames = []string{"Dom", "Lun", "Mar", "Mié", "Jue", "Vie", "Sáb"}
+
+// Get arma el dashboard con los filtros de la query (start_date, end_date y el resto de filtros comunes)
+func (h *DashboardHandler) Get(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.api.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	svc := h.api.service
+	q := r.URL.Query()
+	_, cookieErr := r.Cookie(sessionCookie)
+	view := dashboardView{
+		Session:   h.authn != nil && cookieErr == nil,
+		StartDate: q.Get("start_date"), EndDate: q.Get("end_date"), ExcludeIncognito: f.ExcludeIncognito,
+		BarChartW: barChartW, BarChartH: barChartH, LineChartW: lineChartW, LineChartH: lineChartH,
+	}
+
+	var err error
+	if view.Stats, err = svc.GetDashboardStats(ctx, f); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	artists, err := svc.GetTopArtists(ctx, f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	songs, err := svc.GetTopSongs(ctx, f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	albums, err := svc.GetTopAlbums(ctx, f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	view.TopArtists, view.TopSongs, view.TopAlbums = artists.Data, songs.Data, albums.Data
+
+	timeOfDay, err := svc.GetHabitAnalysis(ctx, "time", f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	dayOfWeek, err := svc.GetHabitAnalysis(ctx, "dow", f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	evolution, err := svc.GetGlobalEvolution(ctx, f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	view.TimeOfDay = barChart(timeOfDayBars(timeOfDay))
+	view.DayOfWeek = barChart(dayOfWeekBars(dayOfWeek))
+	view.Evolution = evolutionChart(evolution)
+
+	// Se renderiza completo antes de responder, un error de plantilla todavía puede informarse
+	var buf bytes.Buffer
+	if err := h.tmpl.Execute(&buf, view); err != nil {
+		writeError(w, r, domain.NewInternalError(err, "No se pudo generar el dashboard"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+func timeOfDayBars(habits []domain.HabitTimeDTO) []chartBar {
+	counts := map[string]int{}
+	for _, h := range habits {
+		counts[h.Label] = h.Count
+	}
+	bars := make([]chartBar, len(timeOfDayOrder))
+	for i, label := range timeOfDayOrder {
+		bars[i] = chartBar{Label: label, Value: counts[label]}
+	}
+	return bars
+}
+
+func dayOfWeekBars(habits []domain.HabitTimeDTO) []chartBar {
+	bars := make([]chartBar, len(dayNames))
+	for i, name := range dayNames {
+		bars[i].Label = name
+	}
+	for _, h := range habits {
+		if h.NumDay != nil && *h.NumDay >= 0 && *h.NumDay < len(bars) {
+			bars[*h.NumDay].Value = h.Count
+		}
+	}
+	return bars
+}
+
+// barChart calcula la geometría de barras verticales proporcionales al máximo
+func barChart(bars []chartBar) []chartBar {
+	maxValue := 0
+	for _, b := range bars {
+		maxValue = max(maxValue, b.Value)
+	}
+	slot := barChartW / float64(len(bars))
+	plotH := barChartH - chartLabelSpace
+	for i := range bars {
+		b := &bars[i]
+		b.W = slot * 0.6
+		b.X = float64(i)*slot + (slot-b.W)/2
+		if maxValue > 0 {
+			b.H = plotH * float64(b.Value) / float64(maxValue)
+		}
+		b.Y = plotH - b.H
+		b.LabelX = b.X + b.W/2
+		b.TextY = barChartH - 8
+	}
+	return bars
+}
+
+// evolutionChart traza las horas mensuales como área, con una marca al inicio de cada año
+func evolutionChart(months []domain.HistoryEvolutionDTO) lineChart {
+	var c lineChart
+	if len(months) == 0 {
+		return c
+	}
+	for _, m := range months {
+		c.Max = math.Max(c.Max, m.HoursMonthly)
+	}
+	plotH := lineChartH - chartLabelSpace
+	step := 0.0
+	if len(months) > 1 {
+		step = lineChartW / float64(len(months)-1)
+	}
+
+	points := make([]string, len(months))
+	for i, m := range months {
+		x := float64(i) * step
+		y := plotH
+		if c.Max > 0 {
+			y = plotH - plotH*m.HoursMonthly/c.Max
+		}
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		if i == 0 || m.Month == "01" {
+			c.Years = append(c.Years, chartTick{Label: m.Year, X: x})
+		}
+	}
+	c.Points = strings.Join(points, " ")
+	lastX := float64(len(months)-1) * step
+	c.Area = fmt.Sprintf("0,%.1f %s %.1f,%.1f", plotH, c.Points, lastX, plotH)
+	return c
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/auth"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// sessionCookie guarda la sesión firmada (auth.Session) emitida al ingresar al dashboard, nunca
+// la credencial. Solo la lee el dashboard: la API JSON sigue exigiendo los headers, así un sitio ajeno no
+// puede usar la cookie para escribir en nombre del usuario
+const sessionCookie = "dashboard_session"
+
+// dashboardLoginForm documenta el formulario de ingreso en la especificación OpenAPI
+type dashboardLoginForm struct {
+	Credential string `json:"credential"` // API key o JWT
+}
+
+type loginView struct {
+	Error string
+}
+
+// withSession acepta la cookie de sesión cuando la petición no trae credenciales en los headers.
+// Sin sesión válida responde el formulario de ingreso en vez del 401 JSON de la API
+func (h *DashboardHandler) withSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := domain.IdentityFromContext(r.Context()); ok || h.authn == nil {
+			next(w, r)
+			return
+		}
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			h.renderLogin(w, r, "")
+			return
+		}
+		id, err := h.authn.AuthenticateSession(r.Context(), cookie.Value)
+		switch {
+		case err == nil:
+			next(w, r.WithContext(domain.WithIdentity(r.Context(), id)))
+		case errors.Is(err, auth.ErrNoCredentials) || classifyError(err).Kind == domain.ErrKindUnauthorized:
+			h.clearSession(w, r)
+			h.renderLogin(w, r, "La sesión ya no es válida, vuelve a ingresar")
+		default:
+			writeError(w, r, err)
+		}
+	}
+}
+
+// Login valida la credencial del formulario y emite la cookie de sesión
+func (h *DashboardHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if h.authn == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	// Un formulario enviado desde otro sitio podría dejar al navegador con la sesión de otra persona
+	if !sameOrigin(r) {
+		writeError(w, r, domain.NewForbiddenError("El ingreso al dashboard debe enviarse desde el mismo sitio"))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 16<<10)
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, domain.NewValidationError("Formulario de ingreso inválido: %v", err))
+		return
+	}
+
+	session, err := h.authn.NewSession(r.Context(), r.PostForm.Get("credential"))
+	switch {
+	case errors.Is(err, auth.ErrNoCredentials):
+		h.renderLogin(w, r, "Ingresa una API key o un token")
+		return
+	case err != nil && classifyError(err).Kind == domain.ErrKindUnauthorized:
+		h.renderLogin(w, r, "Credencial inválida")
+		return
+	case err != nil:
+		writeError(w, r, err)
+		return
+	}
+
+	// Lax y no Strict: un enlace al dashboard desde otro sitio conserva la sesión, y la cookie
+	// solo autoriza lecturas (GET /)
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Value: session.Value, Path: "/", Expires: session.ExpiresAt,
+		HttpOnly: true, Secure: h.secureCookies(r), SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// Logout borra la cookie de sesión
+func (h *DashboardHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	h.clearSession(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (h *DashboardHandler) clearSession(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Path: "/", MaxAge: -1,
+		HttpOnly: true, Secure: h.secureCookies(r), SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// secureCookies marca la cookie Secure si la petición llegó por TLS o si SESSION_COOKIE_SECURE lo pide
+// (TLS terminado en un proxy, donde r.TLS es nil)
+func (h *DashboardHandler) secureCookies(r *http.Request) bool {
+	return r.TLS != nil || (h.authn != nil && h.authn.SecureCookies())
+}
+
+// sameOrigin acepta peticiones sin Origin (clientes que no lo envían) o con el mismo host
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// renderLogin responde el formulario de ingreso con status 401
+func (h *DashboardHandler) renderLogin(w http.ResponseWriter, r *http.Request, msg string) {
+	var buf bytes.Buffer
+	if err := h.tmpl.ExecuteTemplate(&buf, "login.html", loginView{Error: msg}); err != nil {
+		writeError(w, r, domain.NewInternalError(err, "No se pudo generar el formulario de ingreso"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="my-spotify-data"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	buf.WriteTo(w)
+}
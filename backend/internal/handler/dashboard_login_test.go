@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/auth"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// dashboardStub entrega un dashboard vacío
+type dashboardStub struct{ stubSpotifyService }
+
+func (dashboardStub) GetDashboardStats(ctx context.Context, f domain.SpotifyFilters) (domain.TotalStatsDTO, error) {
+	return domain.TotalStatsDTO{}, nil
+}
+
+func (dashboardStub) GetTopArtists(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.ArtistRankingDTO], error) {
+	return domain.Pagination[domain.ArtistRankingDTO]{}, nil
+}
+
+func (dashboardStub) GetTopSongs(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.SongRankingDTO], error) {
+	return domain.Pagination[domain.SongRankingDTO]{}, nil
+}
+
+func (dashboardStub) GetTopAlbums(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.AlbumRankingDTO], error) {
+	return domain.Pagination[domain.AlbumRankingDTO]{}, nil
+}
+
+func (dashboardStub) GetHabitAnalysis(ctx context.Context, habitType string, f domain.SpotifyFilters) ([]domain.HabitTimeDTO, error) {
+	return []domain.HabitTimeDTO{}, nil
+}
+
+func (dashboardStub) GetGlobalEvolution(ctx context.Context, f domain.SpotifyFilters) ([]domain.HistoryEvolutionDTO, error) {
+	return []domain.HistoryEvolutionDTO{}, nil
+}
+
+type usersStub map[string]int
+
+func (u usersStub) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	id, ok := u[username]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	return domain.User{ID: id, Username: username}, nil
+}
+
+func newDashboardRouter(t *testing.T) http.Handler {
+	t.Helper()
+	return newDashboardRouterWith(t, config.AuthConfig{})
+}
+
+func newDashboardRouterWith(t *testing.T, cfg config.AuthConfig) http.Handler {
+	t.Helper()
+	cfg.APIKeys = []config.APIKey{{Key: "llave-ana", Username: "ana", Role: "reader"}}
+	authn, err := auth.New(cfg, usersStub{"ana": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRouter(dashboardStub{}, stubPresetService{}, stubExclusionService{}, stubShareService{}, authn, config.CORSConfig{AllowedOrigins: []string{"*"}})
+}
+
+func loginRequest(credential string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/dashboard/login", strings.NewReader(url.Values{"credential": {credential}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestDashboardSession(t *testing.T) {
+	router := newDashboardRouter(t)
+	get := func(cookie *http.Cookie, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		return rec
+	}
+	isLogin := func(rec *httptest.ResponseRecorder) bool {
+		return rec.Code == http.StatusUnauthorized && strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") &&
+			strings.Contains(rec.Body.String(), `action="/dashboard/login"`)
+	}
+
+	// Sin credenciales el navegador recibe el formulario, no el 401 JSON de la API
+	if rec := get(nil, nil); !isLogin(rec) {
+		t.Fatalf("sin sesión: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	// Los headers siguen funcionando sin cookie
+	if rec := get(nil, map[string]string{"X-API-Key": "llave-ana"}); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "/dashboard/logout") {
+		t.Fatalf("con X-API-Key: %d", rec.Code)
+	}
+
+	// Credencial inválida: se vuelve a mostrar el formulario con el error y sin cookie
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, loginRequest("otra-llave"))
+	if !isLogin(rec) || !strings.Contains(rec.Body.String(), "Credencial inválida") || len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("login inválido: %d %v", rec.Code, rec.Result().Cookies())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, loginRequest("llave-ana"))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/" {
+		t.Fatalf("login: %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != sessionCookie || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("cookie de sesión: %+v", cookies)
+	}
+	// La cookie es una sesión firmada con expiración, no la API key
+	if strings.Contains(cookies[0].Value, "llave-ana") || cookies[0].Expires.IsZero() || cookies[0].Secure {
+		t.Errorf("cookie de sesión: %+v", cookies[0])
+	}
+	session := cookies[0]
+
+	if rec := get(session, nil); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/dashboard/logout") {
+		t.Fatalf("con sesión: %d", rec.Code)
+	}
+
+	// Una sesión alterada (o la API key puesta directo en la cookie) se rechaza y la cookie se borra
+	rec = get(&http.Cookie{Name: sessionCookie, Value: "llave-ana"}, nil)
+	if !isLogin(rec) || !strings.Contains(rec.Body.String(), "vuelve a ingresar") {
+		t.Fatalf("sesión inválida: %d", rec.Code)
+	}
+	if c := rec.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Errorf("la cookie inválida no se borró: %+v", c)
+	}
+
+	// La cookie solo vale para el dashboard: la API JSON sigue exigiendo los headers
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/presets", nil)
+	r.AddCookie(session)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, r)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("la API aceptó la cookie del dashboard: %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dashboard/logout", nil))
+	if c := rec.Result().Cookies(); rec.Code != http.StatusSeeOther || len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Errorf("logout: %d %+v", rec.Code, c)
+	}
+}
+
+// Con el TLS terminado en un proxy r.TLS es nil: SESSION_COOKIE_SECURE marca la cookie igual
+func TestDashboardSecureCookie(t *testing.T) {
+	for _, secure := range []bool{false, true} {
+		router := newDashboardRouterWith(t, config.AuthConfig{SecureCookies: secure})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, loginRequest("llave-ana"))
+		if c := rec.Result().Cookies(); len(c) != 1 || c[0].Secure != secure {
+			t.Errorf("SecureCookies=%v: %+v", secure, c)
+		}
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dashboard/logout", nil))
+		if c := rec.Result().Cookies(); len(c) != 1 || c[0].Secure != secure {
+			t.Errorf("logout con SecureCookies=%v: %+v", secure, c)
+		}
+	}
+}
+
+func TestDashboardLoginRejectsCrossSite(t *testing.T) {
+	router := newDashboardRouter(t)
+	tests := []struct {
+		origin     string
+		wantStatus int
+	}{
+		{"", http.StatusSeeOther},
+		{"http://example.com", http.StatusSeeOther}, // httptest usa example.com como host
+		{"https://otro-sitio.example", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := loginRequest("llave-ana")
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("Origin %q: status %d, se esperaba %d", tt.origin, rec.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestDashboardWithoutAuth(t *testing.T) {
+	router := NewRouter(dashboardStub{}, stubPresetService{}, stubExclusionService{}, stubShareService{}, nil, config.CORSConfig{AllowedOrigins: []string{"*"}})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "/dashboard/logout") {
+		t.Errorf("sin autenticación el dashboard debe abrir directo: %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, loginRequest("lo-que-sea"))
+	if rec.Code != http.StatusSeeOther || len(rec.Result().Cookies()) != 0 {
+		t.Errorf("login sin autenticación: %d %+v", rec.Code, rec.Result().Cookies())
+	}
+}
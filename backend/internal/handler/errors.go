@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// problemDetail es el cuerpo de error según RFC 7807 (application/problem+json)
+type problemDetail struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail"`
+	Instance  string           `json:"instance"`
+	Code      domain.ErrorKind `json:"code"`
+	RequestID string           `json:"request_id,omitempty"`
+
+	InvalidParams []domain.FieldError `json:"invalid_params,omitempty"` // Extensión para errores de validación
+}
+
+var errorStatus = map[domain.ErrorKind]int{
+	domain.ErrKindValidation: http.StatusBadRequest,
+	domain.ErrKindNotFound:   http.StatusNotFound,
+	domain.ErrKindInternal:   http.StatusInternalServerError,
+	domain.ErrKindTimeout:    http.StatusGatewayTimeout,
+
+	domain.ErrKindUnauthorized:     http.StatusUnauthorized,
+	domain.ErrKindForbidden:        http.StatusForbidden,
+	domain.ErrKindMethodNotAllowed: http.StatusMethodNotAllowed,
+}
+
+// writeError traduce cualquier error a un problem document. Los errores que no son
+// domain.AppError se tratan como internos: se registran completos y al cliente solo
+// llega un mensaje genérico, así no se filtran detalles de pgx/SQL
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := classifyError(err)
+	status := errorStatus[appErr.Kind]
+	reqID := requestIDFromContext(r.Context())
+
+	if appErr.Kind == domain.ErrKindInternal || appErr.Kind == domain.ErrKindTimeout {
+		log.Printf("ERROR [%s] %s %s: %v", reqID, r.Method, r.URL.Path, err)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problemDetail{
+		Type:      "/errors/" + string(appErr.Kind),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    appErr.Message,
+		Instance:  r.URL.Path,
+		Code:      appErr.Kind,
+		RequestID: reqID,
+
+		InvalidParams: appErr.Fields,
+	})
+}
+
+func classifyError(err error) *domain.AppError {
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	// 57014 = query_canceled (statement_timeout o cancelación)
+	var pgErr *pgconn.PgError
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &pgErr) && pgErr.Code == "57014") {
+		return &domain.AppError{Kind: domain.ErrKindTimeout, Message: "La consulta tardó demasiado en responder", Err: err}
+	}
+
+	return &domain.AppError{Kind: domain.ErrKindInternal, Message: "Error interno del servidor", Err: err}
+}
+
+// Métodos que se prueban al buscar los permitidos de una ruta
+var routeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// notFound atiende lo que ninguna ruta acepta. El patrón "/" sin método tiene prioridad sobre
+// el 405 automático de ServeMux, por eso aquí se buscan los métodos que sí existen para la ruta:
+// si hay alguno la respuesta es 405 con Allow, si no 404
+func (a *apiRouter) notFound(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range routeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := a.mux.Handler(probe); pattern != "" && pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, r, domain.NewMethodNotAllowedError("La ruta %s no acepta el método %s", r.URL.Path, r.Method))
+		return
+	}
+	writeError(w, r, domain.NewNotFoundError("La ruta %s no existe", r.URL.Path))
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassifyError(t *testing.T) {
+	canceled := &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}
+	tests := []struct {
+		name string
+		err  error
+		want domain.ErrorKind
+	}{
+		{"error de dominio", domain.NewNotFoundError("no existe"), domain.ErrKindNotFound},
+		{"error de dominio envuelto", fmt.Errorf("contexto: %w", domain.NewValidationError("inválido")), domain.ErrKindValidation},
+		{"statement_timeout", canceled, domain.ErrKindTimeout},
+		// Los repositorios envuelven con %w: el código de Postgres debe seguir visible
+		{"statement_timeout envuelto", fmt.Errorf("error al contar canciones: %w", canceled), domain.ErrKindTimeout},
+		{"plazo del contexto", fmt.Errorf("error al buscar: %w", context.DeadlineExceeded), domain.ErrKindTimeout},
+		{"otro error de Postgres", fmt.Errorf("error: %w", &pgconn.PgError{Code: "42P01"}), domain.ErrKindInternal},
+		{"error cualquiera", errors.New("conexión rechazada"), domain.ErrKindInternal},
+	}
+	for _, tt := range tests {
+		if got := classifyError(tt.err).Kind; got != tt.want {
+			t.Errorf("%s: %s, se esperaba %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownRoutes(t *testing.T) {
+	router := newStubRouter(config.CORSConfig{AllowedOrigins: []string{"*"}})
+	tests := []struct {
+		method, path string
+		wantStatus   int
+		wantAllow    string
+	}{
+		{http.MethodPost, "/api/v1/spotify/stats", http.StatusMethodNotAllowed, "GET, HEAD"},
+		{http.MethodDelete, "/api/v1/presets", http.StatusMethodNotAllowed, "GET, HEAD, POST"},
+		{http.MethodPost, "/api/v1/presets/3", http.StatusMethodNotAllowed, "GET, HEAD, PUT, DELETE"},
+		{http.MethodGet, "/api/v1/no-existe", http.StatusNotFound, ""},
+		{http.MethodPost, "/api/v1/no-existe", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.wantStatus || rec.Header().Get("Allow") != tt.wantAllow {
+			t.Errorf("%s %s: %d Allow=%q, se esperaba %d Allow=%q", tt.method, tt.path, rec.Code, rec.Header().Get("Allow"), tt.wantStatus, tt.wantAllow)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s %s: Content-Type %q", tt.method, tt.path, ct)
+		}
+	}
+}
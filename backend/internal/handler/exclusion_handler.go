@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/service"
+)
+
+type ExclusionHandler struct {
+	service service.ExclusionService
+}
+
+func NewExclusionHandler(s service.ExclusionService) *ExclusionHandler {
+	return &ExclusionHandler{service: s}
+}
+
+func (h *ExclusionHandler) List(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ExclusionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ExclusionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in domain.ExcludedEntityInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		writeError(w, r, domain.NewValidationError("Cuerpo JSON inválido: %v", err))
+		return
+	}
+	res, err := h.service.Create(r.Context(), in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(res.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *ExclusionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
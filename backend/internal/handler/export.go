@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/export"
+)
+
+// Extensión de archivo sugerida en Content-Disposition
+var exportExtensions = map[export.Format]string{
+	export.FormatCSV:    "csv",
+	export.FormatNDJSON: "ndjson",
+	export.FormatXLSX:   "xlsx",
+}
+
+// negotiateFormat resuelve format= / Accept. Si retorna false ya se respondió el error
+func negotiateFormat(w http.ResponseWriter, r *http.Request) (export.Format, bool) {
+	format, err := export.Negotiate(r)
+	if err != nil {
+		writeError(w, r, domain.NewFieldsValidationError([]domain.FieldError{{Field: "format", Reason: err.Error()}}))
+		return "", false
+	}
+	return format, true
+}
+
+// writeExport exporta uno de los listados agregados. Sin limit explícito (en la query o en el
+// preset) se exporta el listado completo: parseSpotifyFilters solo asigna Limit si viene
+func (h *SpotifyHandler) writeExport(w http.ResponseWriter, r *http.Request, format export.Format, dataset domain.ExportDataset, f domain.SpotifyFilters) {
+	if f.Limit == 0 {
+		f.Unpaged = true
+	}
+	streamExport(w, r, format, dataset, func(ctx context.Context, fn func(domain.ExportRow) error) error {
+		return h.service.Export(ctx, dataset, f, fn)
+	})
+}
+
+// streamExport transmite las filas en el formato pedido a medida que llegan desde la base de datos.
+// Un error antes del primer byte se responde como problem document; después solo puede
+// registrarse y cortar la respuesta
+func streamExport(w http.ResponseWriter, r *http.Request, format export.Format, dataset domain.ExportDataset, stream func(ctx context.Context, fn func(domain.ExportRow) error) error) {
+	// Una exportación completa puede superar el WriteTimeout del servidor, pensado para respuestas JSON
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("ERROR [%s] no se pudo extender el plazo de escritura: %v", requestIDFromContext(r.Context()), err)
+	}
+
+	cw := &countingWriter{w: w}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+string(dataset)+"."+exportExtensions[format]+`"`)
+
+	writer, err := export.NewWriter(format, cw, dataset.Header())
+	if err == nil {
+		// Por lo mismo, las consultas de la exportación no tienen statement_timeout
+		err = stream(domain.WithoutQueryTimeout(r.Context()), writer.WriteRow)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil {
+		return
+	}
+	if cw.n == 0 {
+		w.Header().Del("Content-Disposition")
+		writeError(w, r, err)
+		return
+	}
+	log.Printf("ERROR [%s] exportación %s interrumpida tras %d bytes: %v", requestIDFromContext(r.Context()), dataset, cw.n, err)
+}
+
+// countingWriter registra cuántos bytes se enviaron al cliente
+type countingWriter struct {
+	w http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
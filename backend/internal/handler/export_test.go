@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// exportSpy registra los filtros con que se pidió la exportación
+type exportSpy struct {
+	stubSpotifyService
+	got domain.SpotifyFilters
+}
+
+func (s *exportSpy) Export(ctx context.Context, dataset domain.ExportDataset, f domain.SpotifyFilters, fn func(domain.ExportRow) error) error {
+	s.got = f
+	return nil
+}
+
+type presetWithLimit struct{ stubPresetService }
+
+func (presetWithLimit) Resolve(ctx context.Context, name string) (domain.PresetParams, error) {
+	return domain.PresetParams{"limit": {"5"}}, nil
+}
+
+func TestExportLimit(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantLimit   int
+		wantUnpaged bool
+	}{
+		{"", 0, true},
+		{"&limit=3", 3, false},
+		{"&preset=top5", 5, false},
+		{"&preset=top5&limit=20", 20, false},
+	}
+	for _, tt := range tests {
+		spy := &exportSpy{}
+		router := NewRouter(spy, presetWithLimit{}, stubExclusionService{}, stubShareService{}, nil, config.CORSConfig{AllowedOrigins: []string{"*"}})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/spotify/top/artists?format=csv"+tt.query, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status %d: %s", tt.query, rec.Code, rec.Body)
+		}
+		if spy.got.Limit != tt.wantLimit || spy.got.Unpaged != tt.wantUnpaged {
+			t.Errorf("%q: Limit=%d Unpaged=%v, se esperaba Limit=%d Unpaged=%v",
+				tt.query, spy.got.Limit, spy.got.Unpaged, tt.wantLimit, tt.wantUnpaged)
+		}
+	}
+}
@@ -1,19 +1,107 @@
 package handler
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/auth"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
 )
 
+type ctxKey int
+
+const requestIDKey ctxKey = iota
+
+// RequestID asigna un identificador a cada petición (o respeta el X-Request-ID recibido)
+// para correlacionar el log con la respuesta de error
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 64 {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// Authenticate resuelve la identidad de cada petición y la deja en el contexto, desde donde
+// los repositorios acotan las consultas al usuario. Credenciales inválidas responden 401 aquí mismo;
+// sin credenciales la petición sigue anónima y cada ruta decide si lo permite (ver requireAccess).
+// Con la autenticación deshabilitada (a == nil) todo se atiende como el usuario por defecto
+func Authenticate(a *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a == nil {
+				id := domain.Identity{UserID: domain.DefaultUserID, Username: domain.DefaultUsername, Role: domain.RoleAdmin}
+				next.ServeHTTP(w, r.WithContext(domain.WithIdentity(r.Context(), id)))
+				return
+			}
+			id, err := a.Authenticate(r)
+			switch {
+			case errors.Is(err, auth.ErrNoCredentials):
+				next.ServeHTTP(w, r)
+			case err != nil:
+				writeAuthError(w, r, err)
+			default:
+				next.ServeHTTP(w, r.WithContext(domain.WithIdentity(r.Context(), id)))
+			}
+		})
+	}
+}
+
+// requireAccess exige una identidad con el rol de la ruta. Las rutas públicas pasan sin revisar
+func requireAccess(level access, next http.HandlerFunc) http.HandlerFunc {
+	if level == accessPublic {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := domain.IdentityFromContext(r.Context())
+		if !ok {
+			writeAuthError(w, r, domain.NewUnauthorizedError("Se requiere autenticación: header X-API-Key o Authorization: Bearer <jwt>"))
+			return
+		}
+		if !id.Role.Allows(level.role()) {
+			writeError(w, r, domain.NewForbiddenError("La ruta requiere rol %s", level.role()))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeAuthError agrega WWW-Authenticate a los 401, como pide RFC 7235
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	if classifyError(err).Kind == domain.ErrKindUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="my-spotify-data"`)
+	}
+	writeError(w, r, err)
+}
+
 // Logger registra detalles de cada petición
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		next.ServeHTTP(w, r)
 		log.Printf(
-			"%s %s %s %s",
+			"[%s] %s %s %s %s",
+			requestIDFromContext(r.Context()),
 			r.Method,
 			r.RequestURI,
 			r.RemoteAddr,
@@ -22,20 +110,64 @@ func Logger(next http.Handler) http.Handler {
 	})
 }
 
-// CORS configura los permisos para peticiones desde el frontend
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// CORS configura los permisos para peticiones desde el frontend según la lista de orígenes permitidos.
+// Solo los orígenes de la lista reciben cabeceras CORS; un preflight de otro origen se rechaza con 403
+func CORS(cfg config.CORSConfig) func(http.Handler) http.Handler {
+	anyOrigin := cfg.AllowsAnyOrigin()
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		origins[o] = true
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Con una lista concreta la respuesta depende del Origin, los caches no deben mezclarlas
+			if !anyOrigin {
+				w.Header().Add("Vary", "Origin")
+			}
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			allowed := anyOrigin || origins[strings.ToLower(origin)]
+			if !allowed {
+				if preflight {
+					writeError(w, r, domain.NewForbiddenError("Origen %q no permitido", origin))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				if cfg.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // Recovery evita que el servidor muera por un panic
@@ -44,17 +176,46 @@ func Recovery(next http.Handler) http.Handler {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("PANIC: %v\n%s", err, debug.Stack())
-				http.Error(w, "Error interno del servidor", http.StatusInternalServerError)
+				writeError(w, r, domain.NewInternalError(fmt.Errorf("panic: %v", err), "Error interno del servidor"))
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
-// JSONResponse asegura que todas las respuestas tengan el header correcto
+// JSONResponse usa application/json como Content-Type por defecto. Los handlers que
+// responden otro formato (CSV, XLSX, problem+json, HTML) lo fijan antes de escribir y se respeta
 func JSONResponse(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(&defaultTypeWriter{ResponseWriter: w, contentType: "application/json"}, r)
 	})
 }
+
+// defaultTypeWriter fija el Content-Type solo si el handler no lo definió
+type defaultTypeWriter struct {
+	http.ResponseWriter
+	contentType string
+	wroteHeader bool
+}
+
+func (d *defaultTypeWriter) WriteHeader(status int) {
+	if !d.wroteHeader {
+		d.wroteHeader = true
+		if d.Header().Get("Content-Type") == "" {
+			d.Header().Set("Content-Type", d.contentType)
+		}
+	}
+	d.ResponseWriter.WriteHeader(status)
+}
+
+func (d *defaultTypeWriter) Write(p []byte) (int, error) {
+	if !d.wroteHeader {
+		d.WriteHeader(http.StatusOK)
+	}
+	return d.ResponseWriter.Write(p)
+}
+
+// Unwrap permite a http.ResponseController llegar al writer original (Flush, deadlines)
+func (d *defaultTypeWriter) Unwrap() http.ResponseWriter {
+	return d.ResponseWriter
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+)
+
+func TestCORS(t *testing.T) {
+	list := config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "X-API-Key"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+	open := config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowedHeaders: []string{"Content-Type"}}
+
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantVary    []string
+		wantMaxAge  string
+		wantMethods string
+		wantCreds   string
+	}{
+		{name: "origen permitido", cfg: list, method: http.MethodGet, origin: "https://app.example.com",
+			wantStatus: http.StatusOK, wantOrigin: "https://app.example.com", wantVary: []string{"Origin"}, wantCreds: "true"},
+		{name: "el origen se compara sin distinguir mayúsculas", cfg: list, method: http.MethodGet, origin: "https://APP.example.com",
+			wantStatus: http.StatusOK, wantOrigin: "https://APP.example.com", wantVary: []string{"Origin"}, wantCreds: "true"},
+		{name: "origen no permitido: sin cabeceras CORS", cfg: list, method: http.MethodGet, origin: "https://evil.example.com",
+			wantStatus: http.StatusOK, wantVary: []string{"Origin"}},
+		{name: "subdominio no listado", cfg: list, method: http.MethodGet, origin: "https://x.app.example.com",
+			wantStatus: http.StatusOK, wantVary: []string{"Origin"}},
+		{name: "sin Origin", cfg: list, method: http.MethodGet,
+			wantStatus: http.StatusOK, wantVary: []string{"Origin"}},
+		{name: "preflight permitido", cfg: list, method: http.MethodOptions, origin: "https://app.example.com", preflight: true,
+			wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com", wantMaxAge: "600", wantMethods: "GET, POST", wantCreds: "true",
+			wantVary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}},
+		{name: "preflight de otro origen", cfg: list, method: http.MethodOptions, origin: "https://evil.example.com", preflight: true,
+			wantStatus: http.StatusForbidden, wantVary: []string{"Origin"}},
+		{name: "comodín sin Vary: Origin", cfg: open, method: http.MethodGet, origin: "https://cualquiera.example.com",
+			wantStatus: http.StatusOK, wantOrigin: "*"},
+		{name: "preflight con comodín y sin max-age", cfg: open, method: http.MethodOptions, origin: "https://cualquiera.example.com", preflight: true,
+			wantStatus: http.StatusNoContent, wantOrigin: "*", wantMethods: "GET",
+			wantVary: []string{"Access-Control-Request-Method", "Access-Control-Request-Headers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			req := httptest.NewRequest(tt.method, "/api/v1/spotify/stats", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			CORS(tt.cfg)(next).ServeHTTP(rec, req)
+
+			h := rec.Header()
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if got := h.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin %q, se esperaba %q", got, tt.wantOrigin)
+			}
+			if got := h.Get("Access-Control-Allow-Credentials"); got != tt.wantCreds {
+				t.Errorf("Allow-Credentials %q, se esperaba %q", got, tt.wantCreds)
+			}
+			if got := h.Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+				t.Errorf("Max-Age %q, se esperaba %q", got, tt.wantMaxAge)
+			}
+			if got := h.Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Allow-Methods %q, se esperaba %q", got, tt.wantMethods)
+			}
+			if got := h.Values("Vary"); !slices.Equal(got, tt.wantVary) {
+				t.Errorf("Vary %q, se esperaba %q", got, tt.wantVary)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/export"
+)
+
+// routeDoc describe una ruta para la especificación OpenAPI.
+// Toda ruta se registra junto a su documentación (ver apiRouter.handle),
+// así la especificación no puede quedar desalineada con NewRouter
+type routeDoc struct {
+	Summary     string
+	Tag         string
+	Params      []paramDoc    // Parámetros propios de la ruta
+	Filters     bool          // Acepta los filtros comunes de parseSpotifyFilters
+	Body        interface{}   // Cuerpo de la petición (POST/PUT)
+	BodyType    string        // Tipo del cuerpo, por defecto application/json
+	Response    interface{}   // Valor del DTO de respuesta, se documenta por reflexión
+	OneOf       []interface{} // Respuestas alternativas (ej. top de artistas, canciones o álbumes)
+	ContentType string        // Por defecto application/json
+	Status      int           // Status de éxito, por defecto 200
+	Exportable  bool          // Acepta format= / Accept para exportar como CSV, NDJSON o XLSX
+	Access      access        // Rol requerido, por defecto según el método (ver routeAccess)
+	Session     bool          // Acepta además la cookie de sesión del dashboard
+}
+
+type paramDoc struct {
+	Name        string
+	In          string // query (por defecto) o path
+	Type        string // string, integer, boolean
+	Format      string // date, etc.
+	Description string
+	Enum        []string
+	Required    bool
+	Repeated    bool // Se puede repetir (artist=A&artist=B), se documenta como arreglo
+}
+
+// filterParams son los parámetros que consume parseSpotifyFilters
+var filterParams = []paramDoc{
+	{Name: "start_date", Type: "string", Format: "date", Description: "Fecha inicial (YYYY-MM-DD, hora de Chile)"},
+	{Name: "end_date", Type: "string", Format: "date", Description: "Fecha final inclusiva (YYYY-MM-DD)"},
+	{Name: "search", Type: "string", Description: "Texto contenido en artista, álbum o canción"},
+	{Name: "artist", Type: "string", Repeated: true, Description: "Artista (texto contenido o nombre exacto según match)"},
+	{Name: "track", Type: "string", Repeated: true, Description: "Canción (texto contenido o nombre exacto según match)"},
+	{Name: "match", Type: "string", Enum: []string{"contains", "exact"}, Description: "Comparación de artist, track y sus exclusiones (por defecto contains)"},
+	{Name: "platform", Type: "string", Repeated: true, Description: "Familia de dispositivo", Enum: deviceFamilies()},
+	{Name: "country", Type: "string", Repeated: true, Description: "País de conexión, código ISO de 2 letras (ZZ = desconocido)"},
+	{Name: "exclude_artist", Type: "string", Repeated: true, Description: "Artistas a excluir"},
+	{Name: "exclude_track", Type: "string", Repeated: true, Description: "Canciones a excluir"},
+	{Name: "exclude_platform", Type: "string", Repeated: true, Description: "Familias de dispositivo a excluir", Enum: deviceFamilies()},
+	{Name: "exclude_country", Type: "string", Repeated: true, Description: "Países a excluir"},
+	{Name: "start_hour", Type: "integer", Description: "Hora inicial (0-23). Mayor que end_hour cruza la medianoche (22 a 3)"},
+	{Name: "end_hour", Type: "integer", Description: "Hora final inclusiva (0-23), cada extremo puede usarse solo"},
+	{Name: "weekday", Type: "string", Repeated: true, Description: "Días de la semana separados por coma (sat,sun o 0-6, domingo = 0)"},
+	{Name: "exclude_incognito", Type: "boolean", Description: "Omitir sesiones privadas"},
+	{Name: "include_excluded", Type: "boolean", Description: "Incluir las entidades de la lista de exclusión global"},
+	{Name: "sort_by", Type: "string", Description: "Métrica de los rankings, define orden y posición (por defecto plays)", Enum: rankingSorts()},
+	{Name: "order", Type: "string", Description: "Sentido del orden (por defecto desc)", Enum: []string{"desc", "asc"}},
+	{Name: "limit", Type: "integer", Description: "Items por página (1-500, por defecto 10)"},
+	{Name: "page", Type: "integer", Description: "Página (desde 1)"},
+	{Name: "preset", Type: "string", Description: "Preset guardado que se aplica antes de los parámetros explícitos"},
+	{Name: "strict", Type: "boolean", Description: "Rechazar parámetros inválidos con 400 (siempre activo en /api/v2)"},
+}
+
+// splitParam separa el resultado entre días hábiles y fin de semana
+var splitParam = paramDoc{Name: "split", Type: "string", Enum: []string{"day_type"}, Description: "Separa el resultado en weekday y weekend"}
+
+// formatParam acompaña a las rutas exportables (routeDoc.Exportable)
+var formatParam = paramDoc{
+	Name: "format", Type: "string", Enum: export.Formats(),
+	Description: "Formato de respuesta, alternativa al header Accept. Sin limit se exporta el listado completo",
+}
+
+func rankingSorts() []string {
+	sorts := make([]string, 0, len(domain.RankingSorts))
+	for _, s := range domain.RankingSorts {
+		sorts = append(sorts, string(s))
+	}
+	return sorts
+}
+
+func deviceFamilies() []string {
+	families := make([]string, 0, len(domain.PlatformRules)+1)
+	for _, rule := range domain.PlatformRules {
+		families = append(families, string(rule.Family))
+	}
+	return append(families, string(domain.DeviceOther))
+}
+
+var pathParamRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// buildOpenAPISpec genera el documento OpenAPI 3 a partir de las rutas registradas
+func buildOpenAPISpec(routes []route) ([]byte, error) {
+	gen := &schemaGen{components: map[string]interface{}{}}
+	problem := gen.schema(reflect.TypeOf(problemDetail{}))
+
+	paths := map[string]map[string]interface{}{}
+	for _, rt := range routes {
+		doc := rt.doc
+		var params []interface{}
+		for _, m := range pathParamRe.FindAllStringSubmatch(rt.path, -1) {
+			p := paramDoc{Name: m[1], In: "path", Type: "string", Required: true}
+			for _, own := range doc.Params {
+				if own.Name == m[1] {
+					p = own
+					p.In, p.Required = "path", true
+				}
+			}
+			params = append(params, p.openAPI())
+		}
+		for _, p := range doc.Params {
+			if p.In != "path" && !strings.Contains(rt.path, "{"+p.Name+"}") {
+				params = append(params, p.openAPI())
+			}
+		}
+		if doc.Filters {
+			for _, p := range filterParams {
+				params = append(params, p.openAPI())
+			}
+		}
+		if doc.Exportable {
+			params = append(params, formatParam.openAPI())
+		}
+
+		contentType := doc.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		var body map[string]interface{}
+		switch {
+		case len(doc.OneOf) > 0:
+			alternatives := make([]interface{}, 0, len(doc.OneOf))
+			for _, alt := range doc.OneOf {
+				alternatives = append(alternatives, gen.schema(reflect.TypeOf(alt)))
+			}
+			body = map[string]interface{}{"oneOf": alternatives}
+		case doc.Response != nil:
+			body = gen.schema(reflect.TypeOf(doc.Response))
+		default:
+			body = map[string]interface{}{"type": "string"}
+		}
+
+		content := map[string]interface{}{contentType: map[string]interface{}{"schema": body}}
+		if doc.Exportable {
+			for _, f := range []export.Format{export.FormatCSV, export.FormatNDJSON, export.FormatXLSX} {
+				content[f.ContentType()] = map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}}
+			}
+		}
+
+		status := doc.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		if status != http.StatusNoContent && status != http.StatusSeeOther {
+			success["content"] = content
+		}
+
+		op := map[string]interface{}{
+			"summary":     doc.Summary,
+			"operationId": operationID(rt.method, openAPIPath(rt.path)),
+			"responses": map[string]interface{}{
+				strconv.Itoa(status): success,
+				"default": map[string]interface{}{
+					"description": "Error (RFC 7807)",
+					"content":     map[string]interface{}{"application/problem+json": map[string]interface{}{"schema": problem}},
+				},
+			},
+		}
+		if doc.Tag != "" {
+			op["tags"] = []string{doc.Tag}
+		}
+		switch {
+		case doc.Access == accessPublic:
+			op["security"] = []interface{}{}
+		case doc.Session:
+			op["security"] = []interface{}{
+				map[string]interface{}{"apiKey": []string{}},
+				map[string]interface{}{"bearer": []string{}},
+				map[string]interface{}{"session": []string{}},
+			}
+			op["x-required-role"] = doc.Access.role()
+		default:
+			op["x-required-role"] = doc.Access.role()
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if doc.Body != nil {
+			bodyType := doc.BodyType
+			if bodyType == "" {
+				bodyType = "application/json"
+			}
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{bodyType: map[string]interface{}{"schema": gen.schema(reflect.TypeOf(doc.Body))}},
+			}
+		}
+
+		path := openAPIPath(rt.path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(rt.method)] = op
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "My Spotify Data API",
+			"version":     "2.0.0",
+			"description": "Estadísticas, rankings y wrappeds sobre el historial extendido de Spotify",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.components,
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				// Solo la acepta el dashboard HTML, se obtiene con POST /dashboard/login
+				"session": map[string]interface{}{"type": "apiKey", "in": "cookie", "name": sessionCookie},
+			},
+		},
+		// Cualquiera de las dos credenciales; solo aplica si la autenticación está habilitada
+		"security": []interface{}{
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{"bearer": []string{}},
+		},
+	}
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+func (p paramDoc) openAPI() map[string]interface{} {
+	in := p.In
+	if in == "" {
+		in = "query"
+	}
+	schema := map[string]interface{}{"type": p.Type}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	if len(p.Enum) > 0 {
+		schema["enum"] = p.Enum
+	}
+	if p.Repeated {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	res := map[string]interface{}{"name": p.Name, "in": in, "schema": schema}
+	if p.Description != "" {
+		res["description"] = p.Description
+	}
+	if p.Required {
+		res["required"] = true
+	}
+	return res
+}
+
+// openAPIPath traduce un patrón de ServeMux a ruta OpenAPI: el ancla {$} (solo la ruta exacta) no es un parámetro
+func openAPIPath(path string) string {
+	return strings.TrimSuffix(path, "{$}")
+}
+
+// operationID arma un identificador estable: GET /api/v1/spotify/top/{type} -> get_api_v1_spotify_top_type
+func operationID(method, path string) string {
+	id := strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_", ".", "_").Replace(path)
+	return strings.ToLower(method) + id
+}
+
+// schemaGen traduce tipos Go a JSON Schema siguiendo las etiquetas json
+type schemaGen struct {
+	components map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGen) schema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Ptr:
+		s := g.schema(t.Elem())
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]interface{}{"allOf": []interface{}{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		name := componentName(t)
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = map[string]interface{}{} // Reserva el nombre ante tipos recursivos
+			g.components[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+func (g *schemaGen) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+	g.collectFields(t, props, &required)
+
+	obj := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+// collectFields recorre los campos, aplanando los structs embebidos como lo hace encoding/json
+func (g *schemaGen) collectFields(t reflect.Type, props map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			g.collectFields(field.Type, props, required)
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		props[name] = g.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// componentName limpia los nombres de tipos genéricos, incluso anidados:
+// DayTypeSplit[...domain.Pagination[...domain.SongRankingDTO]] -> DayTypeSplitPaginationSongRankingDTO.
+// Los tipos no exportados (problemDetail) se publican con mayúscula inicial
+func componentName(t reflect.Type) string {
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	name = packagePathRe.ReplaceAllString(name, "")
+	return strings.NewReplacer("[]", "List", "[", "", "]", "", ",", "").Replace(name)
+}
+
+// packagePathRe reconoce la ruta de paquete de los argumentos de tipo (github.com/.../domain.)
+var packagePathRe = regexp.MustCompile(`[\w.\-/]*\.`)
+
+//go:embed docs/index.html
+var docsFS embed.FS
+
+// serveOpenAPI responde el documento ya generado
+func serveOpenAPI(spec []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// serveDocs responde la página de Redoc que consume /api/openapi.json
+func serveDocs(w http.ResponseWriter, r *http.Request) {
+	page, _ := docsFS.ReadFile("docs/index.html")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/service"
+)
+
+// Servicios vacíos: las pruebas de enrutamiento no llegan a llamarlos
+type (
+	stubSpotifyService   struct{ service.SpotifyService }
+	stubPresetService    struct{ service.PresetService }
+	stubExclusionService struct{ service.ExclusionService }
+	stubShareService     struct{ service.ShareService }
+)
+
+func newStubRouter(cors config.CORSConfig) http.Handler {
+	return NewRouter(stubSpotifyService{}, stubPresetService{}, stubExclusionService{}, stubShareService{}, nil, cors)
+}
+
+func TestOpenAPICoversEveryRoute(t *testing.T) {
+	api := registerRoutes(stubSpotifyService{}, stubPresetService{}, stubExclusionService{}, stubShareService{}, nil)
+
+	rec := httptest.NewRecorder()
+	newStubRouter(config.CORSConfig{AllowedOrigins: []string{"*"}}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json: status %d", rec.Code)
+	}
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("especificación inválida: %v", err)
+	}
+
+	versions := map[string]bool{}
+	for _, rt := range api.routes {
+		pattern := rt.method + " " + rt.path
+
+		// La ruta de la especificación es la que de verdad atiende el mux
+		req := httptest.NewRequest(rt.method, samplePath(rt.path), nil)
+		if _, matched := api.mux.Handler(req); matched != pattern {
+			t.Errorf("%s: el mux responde con %q", pattern, matched)
+		}
+
+		// En OpenAPI el ancla {$} de ServeMux no existe: "/{$}" se documenta como "/"
+		path := strings.TrimSuffix(rt.path, "{$}")
+		if _, ok := spec.Paths[path][strings.ToLower(rt.method)]; !ok {
+			t.Errorf("%s no aparece en paths de la especificación (%s)", pattern, path)
+		}
+		for _, v := range []string{"/api/v1/", "/api/v2/"} {
+			if strings.HasPrefix(rt.path, v) {
+				versions[v] = true
+			}
+		}
+	}
+	if !versions["/api/v1/"] || !versions["/api/v2/"] {
+		t.Errorf("faltan rutas versionadas registradas: %v", versions)
+	}
+
+	documented := 0
+	for _, ops := range spec.Paths {
+		documented += len(ops)
+	}
+	if documented != len(api.routes) {
+		t.Errorf("la especificación documenta %d operaciones, hay %d rutas registradas", documented, len(api.routes))
+	}
+}
+
+// samplePath reemplaza los comodines del patrón por un valor concreto
+func samplePath(pattern string) string {
+	path := strings.TrimSuffix(pattern, "{$}")
+	for _, m := range pathParamRe.FindAllString(path, -1) {
+		path = strings.Replace(path, m, "1", 1)
+	}
+	return path
+}
+
+func TestDocsPinsRedocVersion(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newStubRouter(config.CORSConfig{AllowedOrigins: []string{"*"}}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	page := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(page, "redoc.standalone.js") {
+		t.Fatalf("/api/docs: %d", rec.Code)
+	}
+	if strings.Contains(page, "/latest/") {
+		t.Error("la página de documentación carga Redoc sin versión fija")
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// paramParser lee parámetros de la query y acumula los que no se pudieron interpretar,
+// así el modo estricto puede informar todos los errores en una sola respuesta
+type paramParser struct {
+	q    url.Values
+	errs []domain.FieldError
+}
+
+func newParamParser(q url.Values) *paramParser {
+	return &paramParser{q: q}
+}
+
+func (p *paramParser) fail(field, reason string) {
+	p.errs = append(p.errs, domain.FieldError{Field: field, Reason: reason})
+}
+
+// list retorna todos los valores no vacíos de un parámetro repetible (artist=A&artist=B)
+func (p *paramParser) list(name string) []string {
+	var values []string
+	for _, v := range p.q[name] {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// int retorna false si el parámetro no viene o no es un entero
+func (p *paramParser) int(name string) (int, bool) {
+	v := p.q.Get(name)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		p.fail(name, "debe ser un número entero")
+		return 0, false
+	}
+	return n, true
+}
+
+func (p *paramParser) bool(name string) (bool, bool) {
+	v := p.q.Get(name)
+	if v == "" {
+		return false, false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.fail(name, "debe ser true o false")
+		return false, false
+	}
+	return b, true
+}
+
+// date interpreta fechas simples YYYY-MM-DD en la zona horaria indicada
+func (p *paramParser) date(name string, loc *time.Location) (time.Time, bool) {
+	v := p.q.Get(name)
+	if v == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, loc)
+	if err != nil {
+		p.fail(name, "debe tener formato YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// weekdays interpreta weekday=sat,sun (también repetible). Los días desconocidos se informan y omiten
+func (p *paramParser) weekdays(name string) []time.Weekday {
+	var days []time.Weekday
+	for _, v := range p.list(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			d, ok := domain.ParseWeekday(part)
+			if !ok {
+				p.fail(name, fmt.Sprintf("día desconocido %q, use sun, mon, ..., sat o 0-6", part))
+				continue
+			}
+			days = append(days, d)
+		}
+	}
+	return days
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/service"
+)
+
+// Tamaño máximo del cuerpo JSON de presets y exclusiones
+const maxBodySize = 64 << 10
+
+type PresetHandler struct {
+	service service.PresetService
+}
+
+func NewPresetHandler(s service.PresetService) *PresetHandler {
+	return &PresetHandler{service: s}
+}
+
+func (h *PresetHandler) List(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *PresetHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *PresetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	in, ok := decodePresetInput(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.Create(r.Context(), in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(res.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *PresetHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	in, ok := decodePresetInput(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.Update(r.Context(), id, in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *PresetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathID lee el {id} de las rutas de recursos (presets, exclusiones)
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		writeError(w, r, domain.NewFieldsValidationError([]domain.FieldError{{Field: "id", Reason: "debe ser un entero positivo"}}))
+		return 0, false
+	}
+	return id, true
+}
+
+// decodePresetInput lee el cuerpo y valida los parámetros guardados con el mismo parser
+// de la query, en modo estricto: un preset inválido fallaría en cada petición que lo use
+func decodePresetInput(w http.ResponseWriter, r *http.Request) (domain.FilterPresetInput, bool) {
+	var in domain.FilterPresetInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		writeError(w, r, domain.NewValidationError("Cuerpo JSON inválido: %v", err))
+		return in, false
+	}
+
+	var errs []domain.FieldError
+	keys := make([]string, 0, len(in.Params))
+	for key := range in.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !presetParamNames[key] {
+			errs = append(errs, domain.FieldError{Field: "params." + key, Reason: "no es un filtro que se pueda guardar"})
+		}
+	}
+	f, parseErrs := parseSpotifyFilters(url.Values(in.Params))
+	parseErrs = append(parseErrs, f.Validate()...)
+	for _, e := range parseErrs {
+		errs = append(errs, domain.FieldError{Field: "params." + e.Field, Reason: e.Reason})
+	}
+
+	if len(errs) > 0 {
+		writeError(w, r, domain.NewFieldsValidationError(errs))
+		return in, false
+	}
+	return in, true
+}
+
+// presetParamNames son los filtros comunes que se pueden guardar (todos menos page, strict y preset)
+var presetParamNames = func() map[string]bool {
+	names := map[string]bool{}
+	for _, p := range filterParams {
+		if p.Name != "page" && p.Name != "strict" && p.Name != "preset" {
+			names[p.Name] = true
+		}
+	}
+	return names
+}()
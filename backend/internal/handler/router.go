@@ -1,43 +1,332 @@
 package handler
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
 
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/auth"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/card"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/service"
 )
 
-func NewRouter(spotifySvc service.SpotifyService) http.Handler {
-	mux := http.NewServeMux()
-	h := NewSpotifyHandler(spotifySvc)
+// route es una ruta registrada junto a su documentación OpenAPI
+type route struct {
+	method string
+	path   string
+	doc    routeDoc
+}
 
-	// 1. Estadísticas Generales
-	mux.HandleFunc("GET /api/v1/spotify/stats", h.GetStats)
+// apiRouter registra cada ruta en el mux y en la especificación a la vez
+type apiRouter struct {
+	mux     *http.ServeMux
+	routes  []route
+	session func(http.HandlerFunc) http.HandlerFunc // Resuelve la cookie del dashboard en las rutas con Session
+}
 
-	// 2. Rankings (Top List)
-	mux.HandleFunc("GET /api/v1/spotify/top/artists", h.GetTop)
-	mux.HandleFunc("GET /api/v1/spotify/top/songs", h.GetTop)
-	mux.HandleFunc("GET /api/v1/spotify/top/albums", h.GetTop)
+// Nivel de acceso de una ruta. Sin declarar, las lecturas (GET) piden reader y las escrituras admin
+type access int
 
-	// 3. Hábitos (type=time o type=dow)
-	mux.HandleFunc("GET /api/v1/spotify/habits", h.GetHabits)
+const (
+	accessDefault access = iota
+	accessPublic
+	accessReader
+	accessAdmin
+)
 
-	// 4. Evolución Mensual
-	mux.HandleFunc("GET /api/v1/spotify/evolution", h.GetEvolution)
+func (a access) role() domain.Role {
+	if a == accessAdmin {
+		return domain.RoleAdmin
+	}
+	return domain.RoleReader
+}
 
-	// 5. Stats Anuales
-	mux.HandleFunc("GET /api/v1/spotify/yearly", h.GetYearly)
+func routeAccess(method string, doc routeDoc) access {
+	if doc.Access != accessDefault {
+		return doc.Access
+	}
+	if method == http.MethodGet {
+		return accessReader
+	}
+	return accessAdmin
+}
 
-	// 6. Búsqueda de Ranking Específico
-	mux.HandleFunc("GET /api/v1/spotify/search-rank", h.SearchRanking)
+// handle falla al arrancar si una ruta no está documentada, así /api/openapi.json siempre cubre todo NewRouter
+func (a *apiRouter) handle(pattern string, fn http.HandlerFunc, doc routeDoc) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok || doc.Summary == "" {
+		panic(fmt.Sprintf("la ruta %q debe declarar método y documentación OpenAPI", pattern))
+	}
+	doc.Access = routeAccess(method, doc)
+	h := requireAccess(doc.Access, fn)
+	if doc.Session {
+		// La sesión se resuelve antes de exigir el rol
+		h = a.session(h)
+	}
+	a.mux.HandleFunc(pattern, h)
+	a.routes = append(a.routes, route{method: method, path: path, doc: doc})
+}
 
-	// 7. Wrappeds
-	mux.HandleFunc("GET /api/v1/spotify/wrapped", h.GetWrapped)
+// authn nil deshabilita la autenticación (instalación de un solo usuario)
+func NewRouter(spotifySvc service.SpotifyService, presetSvc service.PresetService, exclusionSvc service.ExclusionService, shareSvc service.ShareService, authn *auth.Authenticator, cors config.CORSConfig) http.Handler {
+	api := registerRoutes(spotifySvc, presetSvc, exclusionSvc, shareSvc, authn)
 
-	var handler http.Handler = mux
+	var handler http.Handler = api.mux
 	handler = JSONResponse(handler)
+	handler = Authenticate(authn)(handler)
 	handler = Logger(handler)
-	handler = CORS(handler)
+	handler = CORS(cors)(handler)
 	handler = Recovery(handler)
+	handler = RequestID(handler)
 
 	return handler
 }
+
+// registerRoutes arma el mux con todas las rutas y la especificación OpenAPI que las documenta
+func registerRoutes(spotifySvc service.SpotifyService, presetSvc service.PresetService, exclusionSvc service.ExclusionService, shareSvc service.ShareService, authn *auth.Authenticator) *apiRouter {
+	v1 := NewSpotifyHandler(spotifySvc, presetSvc, false)
+	v2 := NewSpotifyHandler(spotifySvc, presetSvc, true)
+	presets := NewPresetHandler(presetSvc)
+	exclusions := NewExclusionHandler(exclusionSvc)
+	shares := NewShareHandler(shareSvc, v2)
+	dashboard := NewDashboardHandler(v1, authn)
+	api := &apiRouter{mux: http.NewServeMux(), session: dashboard.withSession}
+
+	// /api/v1: contrato original, se mantiene compatible (validación permisiva salvo ?strict=true)
+	registerCommonRoutes(api, "/api/v1", v1)
+	api.handle("GET /api/v1/spotify/search-rank", v1.SearchRanking, routeDoc{
+		Summary: "Posición de un artista o canción en el ranking", Tag: "v1", Filters: true,
+		Params: searchRankParams, OneOf: []interface{}{[]domain.SongRankingDTO{}, []domain.ArtistRankingDTO{}},
+	})
+	api.handle("GET /api/v1/spotify/wrapped", v1.GetWrapped, routeDoc{
+		Summary: "Top 100 canciones del año, mes o estación", Tag: "v1", Filters: true,
+		Params: wrappedParams, Response: []domain.SongRankingDTO{},
+	})
+
+	// /api/v2: DTOs tipados para búsqueda de ranking y wrapped, validación estricta
+	registerCommonRoutes(api, "/api/v2", v2)
+	api.handle("GET /api/v2/spotify/search-rank", v2.SearchRankingV2, routeDoc{
+		Summary: "Posición de un artista o canción en el ranking", Tag: "v2", Filters: true,
+		Params: searchRankParams, Response: domain.RankSearchResultDTO{},
+	})
+	api.handle("GET /api/v2/spotify/wrapped", v2.GetWrappedV2, routeDoc{
+		Summary: "Wrapped completo del año, mes o estación", Tag: "v2", Filters: true,
+		Params: wrappedParams, Response: domain.WrappedDTO{},
+	})
+
+	api.handle("GET /api/v2/spotify/wrapped/card", v2.GetWrappedCard, routeDoc{
+		Summary: "Wrapped como imagen para historias (1080x1920)", Tag: "v2", Filters: true, ContentType: "image/png",
+		Params: append([]paramDoc{
+			{Name: "image", Type: "string", Enum: []string{"png", "svg"}, Description: "Tipo de imagen (por defecto png)"},
+			{Name: "theme", Type: "string", Enum: card.ThemeNames(), Description: "Paleta de colores (por defecto " + card.DefaultTheme + ")"},
+		}, wrappedParams...),
+	})
+
+	// Presets de filtros, se aplican en cualquier endpoint con ?preset=<name>.
+	// Son vistas personales de cada usuario, por eso basta el rol reader para administrarlos
+	api.handle("GET /api/v1/presets", presets.List, routeDoc{
+		Summary: "Lista los presets de filtros guardados", Tag: "presets", Response: []domain.FilterPreset{},
+	})
+	api.handle("POST /api/v1/presets", presets.Create, routeDoc{
+		Summary: "Crea un preset de filtros", Tag: "presets", Access: accessReader, Status: http.StatusCreated,
+		Body: domain.FilterPresetInput{}, Response: domain.FilterPreset{},
+	})
+	api.handle("GET /api/v1/presets/{id}", presets.Get, routeDoc{
+		Summary: "Obtiene un preset de filtros", Tag: "presets", Response: domain.FilterPreset{},
+	})
+	api.handle("PUT /api/v1/presets/{id}", presets.Update, routeDoc{
+		Summary: "Reemplaza un preset de filtros", Tag: "presets", Access: accessReader, Body: domain.FilterPresetInput{}, Response: domain.FilterPreset{},
+	})
+	api.handle("DELETE /api/v1/presets/{id}", presets.Delete, routeDoc{
+		Summary: "Elimina un preset de filtros", Tag: "presets", Access: accessReader, Status: http.StatusNoContent,
+	})
+
+	// Lista de exclusión, se ignora con ?include_excluded=true.
+	// Cada usuario administra la suya: como en los presets, basta el rol reader
+	api.handle("GET /api/v1/exclusions", exclusions.List, routeDoc{
+		Summary: "Lista las entidades excluidas de las estadísticas", Tag: "exclusions", Response: []domain.ExcludedEntity{},
+	})
+	api.handle("POST /api/v1/exclusions", exclusions.Create, routeDoc{
+		Summary: "Excluye un artista, canción o URI de todas las estadísticas", Tag: "exclusions", Access: accessReader,
+		Status: http.StatusCreated, Body: domain.ExcludedEntityInput{}, Response: domain.ExcludedEntity{},
+	})
+	api.handle("GET /api/v1/exclusions/{id}", exclusions.Get, routeDoc{
+		Summary: "Obtiene una entidad excluida", Tag: "exclusions", Response: domain.ExcludedEntity{},
+	})
+	api.handle("DELETE /api/v1/exclusions/{id}", exclusions.Delete, routeDoc{
+		Summary: "Quita una entidad de la lista de exclusión", Tag: "exclusions", Access: accessReader, Status: http.StatusNoContent,
+	})
+
+	// Enlaces públicos a un wrapped congelado. Son del usuario que los crea, basta el rol reader
+	api.handle("GET /api/v1/shares", shares.List, routeDoc{
+		Summary: "Lista los enlaces compartidos", Tag: "shares", Response: []domain.ShareLink{},
+	})
+	api.handle("POST /api/v1/shares", shares.Create, routeDoc{
+		Summary: "Comparte un wrapped: congela el resultado y genera un enlace público", Tag: "shares", Access: accessReader,
+		Status: http.StatusCreated, Filters: true,
+		Params: append([]paramDoc{
+			{Name: "expires_in", Type: "integer", Description: "Horas de vigencia del enlace (por defecto no expira)"},
+		}, wrappedParams...),
+		Response: domain.ShareLink{},
+	})
+	api.handle("GET /api/v1/shares/{id}", shares.GetLink, routeDoc{
+		Summary: "Obtiene un enlace compartido (sin el token)", Tag: "shares", Response: domain.ShareLink{},
+	})
+	api.handle("DELETE /api/v1/shares/{id}", shares.Revoke, routeDoc{
+		Summary: "Revoca un enlace compartido", Tag: "shares", Access: accessReader, Status: http.StatusNoContent,
+	})
+	api.handle("GET /share/{token}", shares.Get, routeDoc{
+		Summary: "Wrapped compartido (público, solo lectura)", Tag: "shares", Access: accessPublic,
+		Response: domain.SharedWrappedDTO{},
+	})
+
+	// Dashboard HTML embebido, para usar el binario sin el frontend. Con autenticación habilitada
+	// el navegador ingresa con un formulario que guarda la credencial en una cookie
+	api.handle("GET /{$}", dashboard.Get, routeDoc{
+		Summary: "Dashboard HTML con estadísticas, tops, hábitos y evolución", Tag: "dashboard", Filters: true, ContentType: "text/html",
+		Session: true,
+	})
+	api.handle("POST /dashboard/login", dashboard.Login, routeDoc{
+		Summary: "Ingreso al dashboard: valida una API key o JWT y abre una sesión firmada en una cookie", Tag: "dashboard", Access: accessPublic,
+		Status: http.StatusSeeOther, Body: dashboardLoginForm{}, BodyType: "application/x-www-form-urlencoded",
+	})
+	api.handle("POST /dashboard/logout", dashboard.Logout, routeDoc{
+		Summary: "Cierra la sesión del dashboard", Tag: "dashboard", Access: accessPublic, Status: http.StatusSeeOther,
+	})
+
+	// Especificación OpenAPI y su visor. El documento se genera al final, con todas las rutas ya registradas
+	var spec []byte
+	api.handle("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		serveOpenAPI(spec)(w, r)
+	}, routeDoc{Summary: "Especificación OpenAPI 3 de la API", Tag: "docs", Access: accessPublic, Response: map[string]interface{}{}})
+	api.handle("GET /api/docs", serveDocs, routeDoc{Summary: "Documentación interactiva (Redoc)", Tag: "docs", Access: accessPublic, ContentType: "text/html"})
+
+	spec, err := buildOpenAPISpec(api.routes)
+	if err != nil {
+		log.Fatalf("Error generando la especificación OpenAPI: %v", err)
+	}
+
+	// Cualquier otra ruta responde 404 (o 405 si existe con otro método) como problem document
+	api.mux.HandleFunc("/", api.notFound)
+	return api
+}
+
+var searchRankParams = []paramDoc{
+	{Name: "target_artist", Type: "string", Description: "Artista a ubicar en el ranking"},
+	{Name: "target_track", Type: "string", Description: "Canción a ubicar (si viene, el ranking es de canciones)"},
+}
+
+var wrappedParams = []paramDoc{
+	{Name: "year", Type: "integer", Required: true, Description: "Año del wrapped"},
+	{Name: "month", Type: "integer", Description: "Mes (1-12) para un wrapped mensual"},
+	{Name: "season", Type: "string", Description: "Estación para un wrapped estacional", Enum: []string{"summer", "autumn", "winter", "spring"}},
+}
+
+// registerCommonRoutes registra las rutas cuyo contrato es igual en todas las versiones
+func registerCommonRoutes(api *apiRouter, prefix string, h *SpotifyHandler) {
+	tag := strings.TrimPrefix(prefix, "/api/")
+
+	// 1. Estadísticas Generales
+	api.handle("GET "+prefix+"/spotify/stats", h.GetStats, routeDoc{
+		Summary: "Horas, minutos y diversidad general", Tag: tag, Filters: true, Params: []paramDoc{splitParam},
+		OneOf: []interface{}{domain.TotalStatsDTO{}, domain.DayTypeSplit[domain.TotalStatsDTO]{}},
+	})
+
+	// 2. Rankings (Top List): artists, songs o albums. Otro tipo responde 400
+	api.handle("GET "+prefix+"/spotify/top/{type}", h.GetTop, routeDoc{
+		Summary: "Ranking paginado de artistas, canciones o álbumes", Tag: tag, Filters: true, Exportable: true,
+		Params: []paramDoc{{Name: "type", In: "path", Type: "string", Enum: []string{"artists", "songs", "albums"}}, splitParam},
+		OneOf: []interface{}{
+			domain.Pagination[domain.ArtistRankingDTO]{},
+			domain.Pagination[domain.SongRankingDTO]{},
+			domain.Pagination[domain.AlbumRankingDTO]{},
+			domain.DayTypeSplit[domain.Pagination[domain.ArtistRankingDTO]]{},
+			domain.DayTypeSplit[domain.Pagination[domain.SongRankingDTO]]{},
+			domain.DayTypeSplit[domain.Pagination[domain.AlbumRankingDTO]]{},
+		},
+	})
+
+	// 3. Hábitos (type=time o type=dow)
+	api.handle("GET "+prefix+"/spotify/habits", h.GetHabits, routeDoc{
+		Summary: "Escuchas por momento del día o día de la semana", Tag: tag, Filters: true, Exportable: true,
+		Params:   []paramDoc{{Name: "type", Type: "string", Enum: []string{"time", "dow"}}},
+		Response: []domain.HabitTimeDTO{},
+	})
+
+	// 4. Evolución Mensual
+	api.handle("GET "+prefix+"/spotify/evolution", h.GetEvolution, routeDoc{
+		Summary: "Evolución mensual de horas y minutos", Tag: tag, Filters: true, Exportable: true, Params: []paramDoc{splitParam},
+		OneOf: []interface{}{[]domain.HistoryEvolutionDTO{}, domain.DayTypeSplit[[]domain.HistoryEvolutionDTO]{}},
+	})
+
+	// 5. Stats Anuales
+	api.handle("GET "+prefix+"/spotify/yearly", h.GetYearly, routeDoc{
+		Summary: "Comparativa anual", Tag: tag, Filters: true, Exportable: true, Response: []domain.YearlyStatsDTO{},
+	})
+
+	// Historial de reproducciones individuales (paginación por cursor)
+	api.handle("GET "+prefix+"/spotify/plays", h.GetPlays, routeDoc{
+		Summary: "Reproducciones individuales con paginación por cursor", Tag: tag, Filters: true, Exportable: true,
+		Params:   []paramDoc{{Name: "cursor", Type: "string", Description: "Valor de next_cursor de la página anterior"}},
+		Response: domain.CursorPagination[domain.SpotifyRecord]{},
+	})
+
+	// Búsqueda aproximada para autocompletar
+	api.handle("GET "+prefix+"/spotify/search", h.GetSearch, routeDoc{
+		Summary: "Búsqueda aproximada de artistas, álbumes y canciones (sin distinguir acentos)", Tag: tag, Filters: true,
+		Params:   []paramDoc{{Name: "q", Type: "string", Required: true, Description: "Término a buscar (mínimo 2 caracteres)"}},
+		Response: domain.SearchResultDTO{},
+	})
+
+	// Compatibilidad de gustos entre dos usuarios
+	api.handle("GET "+prefix+"/spotify/compare-users", h.GetCompareUsers, routeDoc{
+		Summary: "Compatibilidad, artistas y canciones en común entre dos usuarios", Tag: tag, Filters: true,
+		Params: []paramDoc{
+			{Name: "a", Type: "string", Required: true, Description: "Username del primer usuario"},
+			{Name: "b", Type: "string", Required: true, Description: "Username del segundo usuario"},
+		},
+		Response: domain.UserComparisonDTO{},
+	})
+
+	// 6. Diversidad (entropía, Gini, concentración)
+	api.handle("GET "+prefix+"/spotify/diversity", h.GetDiversity, routeDoc{
+		Summary: "Entropía, Gini y concentración de artistas y canciones", Tag: tag, Filters: true, Response: domain.DiversityDTO{},
+	})
+
+	// 7. Rachas de repetición (type=track o type=artist)
+	api.handle("GET "+prefix+"/spotify/binges", h.GetBinges, routeDoc{
+		Summary: "Rachas de reproducciones consecutivas", Tag: tag, Filters: true,
+		Params: []paramDoc{
+			{Name: "type", Type: "string", Enum: []string{"track", "artist"}},
+			{Name: "min_plays", Type: "integer", Description: "Largo mínimo de la racha, al menos 2 (por defecto 3)"},
+			{Name: "max_gap_minutes", Type: "integer", Description: "Pausa máxima entre reproducciones, de 1 a 1440 (por defecto 30)"},
+		},
+		Response: domain.Pagination[domain.BingeDTO]{},
+	})
+
+	// 8. Países y viajes (conn_country)
+	api.handle("GET "+prefix+"/spotify/countries", h.GetCountries, routeDoc{
+		Summary: "Consumo por país y viajes", Tag: tag, Filters: true, Response: domain.CountryAnalyticsDTO{},
+	})
+
+	// 9. Dispositivos (platform normalizado por familia)
+	api.handle("GET "+prefix+"/spotify/platforms", h.GetPlatforms, routeDoc{
+		Summary: "Consumo por familia de dispositivo", Tag: tag, Filters: true, Response: domain.PlatformAnalyticsDTO{},
+	})
+
+	// 10. Origen de las reproducciones (shuffle, autoplay, click, trackdone)
+	api.handle("GET "+prefix+"/spotify/sources", h.GetSources, routeDoc{
+		Summary: "Shuffle, autoplay y reproducciones elegidas", Tag: tag, Filters: true, Response: domain.ListeningSourcesDTO{},
+	})
+
+	// 11. Escucha sin conexión y sesiones privadas
+	api.handle("GET "+prefix+"/spotify/offline", h.GetOffline, routeDoc{
+		Summary: "Escucha sin conexión y retraso de sincronización", Tag: tag, Filters: true, Response: domain.OfflineStatsDTO{},
+	})
+	api.handle("GET "+prefix+"/spotify/incognito", h.GetIncognito, routeDoc{
+		Summary: "Escucha en sesiones privadas", Tag: tag, Filters: true, Response: domain.IncognitoStatsDTO{},
+	})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Presets, exclusiones y enlaces compartidos son de cada usuario: un reader debe poder administrarlos
+func TestPerUserResourcesNeedOnlyReader(t *testing.T) {
+	api := registerRoutes(stubSpotifyService{}, stubPresetService{}, stubExclusionService{}, stubShareService{}, nil)
+	for _, rt := range api.routes {
+		for _, prefix := range []string{"/api/v1/presets", "/api/v1/exclusions", "/api/v1/shares"} {
+			if strings.HasPrefix(rt.path, prefix) && rt.doc.Access != accessReader {
+				t.Errorf("%s %s: acceso %d, se esperaba reader", rt.method, rt.path, rt.doc.Access)
+			}
+		}
+	}
+}
+
+func TestRouteAccessDefaults(t *testing.T) {
+	tests := []struct {
+		method string
+		doc    routeDoc
+		want   access
+	}{
+		{http.MethodGet, routeDoc{}, accessReader},
+		{http.MethodPost, routeDoc{}, accessAdmin},
+		{http.MethodDelete, routeDoc{}, accessAdmin},
+		{http.MethodPost, routeDoc{Access: accessReader}, accessReader},
+		{http.MethodGet, routeDoc{Access: accessPublic}, accessPublic},
+	}
+	for _, tt := range tests {
+		if got := routeAccess(tt.method, tt.doc); got != tt.want {
+			t.Errorf("routeAccess(%s, %d) = %d, se esperaba %d", tt.method, tt.doc.Access, got, tt.want)
+		}
+	}
+}
+
+// Los POST que responden 201 envían Location a /{id}: esa ruta debe existir
+func TestCreatedResourcesHaveGetRoute(t *testing.T) {
+	api := registerRoutes(stubSpotifyService{}, stubPresetService{}, stubExclusionService{}, stubShareService{}, nil)
+	routes := map[string]bool{}
+	for _, rt := range api.routes {
+		routes[rt.method+" "+rt.path] = true
+	}
+	for _, collection := range []string{"/api/v1/presets", "/api/v1/exclusions", "/api/v1/shares"} {
+		if !routes["POST "+collection] || !routes["GET "+collection+"/{id}"] {
+			t.Errorf("%s: falta POST o GET %s/{id}", collection, collection)
+		}
+	}
+}
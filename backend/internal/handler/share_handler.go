@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/service"
+)
+
+// ShareHandler administra los enlaces públicos. El wrapped se obtiene con el handler de /api/v2,
+// así acepta exactamente los mismos parámetros (year, month, season y filtros) con validación estricta
+type ShareHandler struct {
+	service service.ShareService
+	wrapped *SpotifyHandler
+}
+
+func NewShareHandler(s service.ShareService, wrapped *SpotifyHandler) *ShareHandler {
+	return &ShareHandler{service: s, wrapped: wrapped}
+}
+
+func (h *ShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetLink es la vista del dueño: fechas y estado del enlace, sin el token ni el snapshot
+func (h *ShareHandler) GetLink(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetLink(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// Create congela el wrapped pedido en la query. expires_in (horas) es opcional
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	p := newParamParser(r.URL.Query())
+	expiresIn, _ := p.int("expires_in")
+	if len(p.errs) > 0 {
+		writeError(w, r, domain.NewFieldsValidationError(p.errs))
+		return
+	}
+
+	wrapped, ok := h.wrapped.resolveWrapped(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.Create(r.Context(), wrapped, expiresIn)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(res.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
+
+// Revoke invalida el enlace de inmediato; la fila queda en el listado con revoked_at
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Get es la vista pública del snapshot, no requiere credenciales
+func (h *ShareHandler) Get(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Get(r.Context(), r.PathValue("token"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// El enlace puede revocarse en cualquier momento: que ningún cache intermedio lo retenga
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	json.NewEncoder(w).Encode(res)
+}
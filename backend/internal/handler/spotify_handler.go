@@ -1,183 +1,651 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/card"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/export"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/service"
 )
 
 type SpotifyHandler struct {
 	service service.SpotifyService
+	presets service.PresetService
+	strict  bool // Rechaza parámetros inválidos con 400 en vez de ignorarlos
 }
 
-func NewSpotifyHandler(s service.SpotifyService) *SpotifyHandler {
-	return &SpotifyHandler{service: s}
+func NewSpotifyHandler(s service.SpotifyService, presets service.PresetService, strict bool) *SpotifyHandler {
+	return &SpotifyHandler{service: s, presets: presets, strict: strict}
 }
 
-// Helper para parsear los filtros comunes de la URL
-func parseSpotifyFilters(r *http.Request) domain.SpotifyFilters {
+// Helper para parsear los filtros comunes de la URL.
+// Los valores que no se pueden interpretar se omiten y se retornan como errores
+func parseSpotifyFilters(q url.Values) (domain.SpotifyFilters, []domain.FieldError) {
+	p := newParamParser(q)
 	f := domain.SpotifyFilters{
-		Search: r.URL.Query().Get("search"),
-		Artist: r.URL.Query().Get("artist"),
-		Track:  r.URL.Query().Get("track"),
+		Search:    p.q.Get("search"),
+		Artists:   p.list("artist"),
+		Tracks:    p.list("track"),
+		Match:     domain.MatchMode(p.q.Get("match")),
+		Platforms: deviceFamilyList(p.list("platform")),
+		Countries: p.list("country"),
+		SortBy:    domain.RankingSort(p.q.Get("sort_by")),
+		Order:     domain.SortOrder(p.q.Get("order")),
+
+		ExcludeArtists:   p.list("exclude_artist"),
+		ExcludeTracks:    p.list("exclude_track"),
+		ExcludePlatforms: deviceFamilyList(p.list("exclude_platform")),
+		ExcludeCountries: p.list("exclude_country"),
 	}
 
 	// Cargar la zona horaria de Chile
 	loc, _ := time.LoadLocation("America/Santiago")
 
-	if startStr := r.URL.Query().Get("start_date"); startStr != "" {
-		// Intentar parsear como fecha simple YYYY-MM-DD
-		if t, err := time.ParseInLocation("2006-01-02", startStr, loc); err == nil {
-			f.StartDate = &t
-		}
+	if t, ok := p.date("start_date", loc); ok {
+		f.StartDate = &t
 	}
-	if endStr := r.URL.Query().Get("end_date"); endStr != "" {
-		if t, err := time.ParseInLocation("2006-01-02", endStr, loc); err == nil {
-			// Para el EndDate, sumamos 23h 59m para incluir todo el día
-			endOfDay := t.Add(24*time.Hour - time.Second)
-			f.EndDate = &endOfDay
-		}
+	if t, ok := p.date("end_date", loc); ok {
+		// Para el EndDate, sumamos 23h 59m para incluir todo el día
+		endOfDay := t.Add(24*time.Hour - time.Second)
+		f.EndDate = &endOfDay
 	}
-	if hStr := r.URL.Query().Get("start_hour"); hStr != "" {
-		if h, err := strconv.Atoi(hStr); err == nil {
-			f.StartHour = &h
-		}
+	if h, ok := p.int("start_hour"); ok {
+		f.StartHour = &h
 	}
-	if hStr := r.URL.Query().Get("end_hour"); hStr != "" {
-		if h, err := strconv.Atoi(hStr); err == nil {
-			f.EndHour = &h
-		}
+	if h, ok := p.int("end_hour"); ok {
+		f.EndHour = &h
+	}
+	f.Weekdays = p.weekdays("weekday")
+	if b, ok := p.bool("exclude_incognito"); ok {
+		f.ExcludeIncognito = b
+	}
+	if b, ok := p.bool("include_excluded"); ok {
+		f.IncludeExcluded = b
 	}
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil {
+	if l, ok := p.int("limit"); ok {
+		if l < 1 {
+			p.fail("limit", fmt.Sprintf("debe estar entre 1 y %d", domain.MaxLimit))
+		} else {
 			f.Limit = l
 		}
 	}
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil {
-			f.Page = p
+	if pg, ok := p.int("page"); ok {
+		if pg < 1 {
+			p.fail("page", "debe ser mayor o igual a 1")
+		} else {
+			f.Page = pg
 		}
 	}
-	return f
+	return f, p.errs
 }
 
-func (h *SpotifyHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.service.GetDashboardStats(r.Context(), parseSpotifyFilters(r))
+func deviceFamilyList(values []string) []domain.DeviceFamily {
+	var families []domain.DeviceFamily
+	for _, v := range values {
+		families = append(families, domain.DeviceFamily(v))
+	}
+	return families
+}
+
+// parseFilters obtiene los filtros comunes junto a los errores propios del endpoint (extra).
+// En modo estricto (v2 o ?strict=true) cualquier error responde 400 con el detalle por campo;
+// en modo permisivo se ignoran y CleanAndValidate corrige los valores. Si retorna false ya se respondió
+func (h *SpotifyHandler) parseFilters(w http.ResponseWriter, r *http.Request, extra ...domain.FieldError) (domain.SpotifyFilters, bool) {
+	q, err := h.expandPreset(r)
+	if err != nil {
+		writeError(w, r, err)
+		return domain.SpotifyFilters{}, false
+	}
+	f, errs := parseSpotifyFilters(q)
+	errs = append(errs, f.Validate()...)
+	errs = append(errs, extra...)
+
+	if len(errs) > 0 && h.strictMode(r) {
+		writeError(w, r, domain.NewFieldsValidationError(errs))
+		return f, false
+	}
+	return f, true
+}
+
+// expandPreset combina el preset de ?preset= con la query: los parámetros guardados se
+// cargan primero y cualquier parámetro explícito de la petición los reemplaza
+func (h *SpotifyHandler) expandPreset(r *http.Request) (url.Values, error) {
+	q := r.URL.Query()
+	name := q.Get("preset")
+	if name == "" || h.presets == nil {
+		return q, nil
+	}
+	params, err := h.presets.Resolve(r.Context(), name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+
+	merged := url.Values{}
+	for key, values := range params {
+		merged[key] = values
+	}
+	for key, values := range q {
+		if key != "preset" {
+			merged[key] = values
+		}
+	}
+	return merged, nil
+}
+
+func (h *SpotifyHandler) strictMode(r *http.Request) bool {
+	return h.strict || r.URL.Query().Get("strict") == "true"
+}
+
+func (h *SpotifyHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	split, extra := parseSplit(r)
+	f, ok := h.parseFilters(w, r, extra...)
+	if !ok {
 		return
 	}
-	json.NewEncoder(w).Encode(stats)
+	respond(w, r, f, split, h.service.GetDashboardStats)
 }
 
-func (h *SpotifyHandler) GetTop(w http.ResponseWriter, r *http.Request) {
-	f := parseSpotifyFilters(r)
-	listType := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+// parseSplit lee split=day_type, que separa el resultado entre días hábiles y fin de semana
+func parseSplit(r *http.Request) (bool, []domain.FieldError) {
+	switch r.URL.Query().Get("split") {
+	case "":
+		return false, nil
+	case "day_type":
+		return true, nil
+	}
+	return false, []domain.FieldError{{Field: "split", Reason: "debe ser day_type"}}
+}
 
-	res, err := h.service.GetTopList(r.Context(), listType, f)
+// respond codifica el resultado de fetch o, con split, un domain.DayTypeSplit con el resultado de cada tipo de día
+func respond[T any](w http.ResponseWriter, r *http.Request, f domain.SpotifyFilters, split bool, fetch func(context.Context, domain.SpotifyFilters) (T, error)) {
+	var res interface{}
+	var err error
+	if split {
+		res, err = service.SplitByDayType(f, func(f domain.SpotifyFilters) (T, error) {
+			return fetch(r.Context(), f)
+		})
+	} else {
+		res, err = fetch(r.Context(), f)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	json.NewEncoder(w).Encode(res)
 }
 
+// GetTop atiende /top/{type}, cada tipo de lista tiene su propio DTO.
+// Con format=csv|ndjson|xlsx (o el header Accept) se exporta en vez de paginar
+func (h *SpotifyHandler) GetTop(w http.ResponseWriter, r *http.Request) {
+	split, extra := parseSplit(r)
+	f, ok := h.parseFilters(w, r, extra...)
+	if !ok {
+		return
+	}
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+
+	var dataset domain.ExportDataset
+	switch r.PathValue("type") {
+	case "artists":
+		dataset = domain.ExportTopArtists
+	case "songs":
+		dataset = domain.ExportTopSongs
+	case "albums":
+		dataset = domain.ExportTopAlbums
+	default:
+		writeError(w, r, domain.NewValidationError("Tipo de lista inválido. Use: artists, songs o albums"))
+		return
+	}
+	if format != export.FormatJSON {
+		if split {
+			writeError(w, r, domain.NewValidationError("split no está disponible al exportar"))
+			return
+		}
+		h.writeExport(w, r, format, dataset, f)
+		return
+	}
+
+	switch dataset {
+	case domain.ExportTopArtists:
+		respond(w, r, f, split, h.service.GetTopArtists)
+	case domain.ExportTopSongs:
+		respond(w, r, f, split, h.service.GetTopSongs)
+	case domain.ExportTopAlbums:
+		respond(w, r, f, split, h.service.GetTopAlbums)
+	}
+}
+
 func (h *SpotifyHandler) GetHabits(w http.ResponseWriter, r *http.Request) {
 	// habit_type puede ser "time" o "dow" (day of week)
 	hType := r.URL.Query().Get("type")
-	res, err := h.service.GetHabitAnalysis(r.Context(), hType, parseSpotifyFilters(r))
+	var extra []domain.FieldError
+	if hType != "" && hType != "time" && hType != "dow" {
+		extra = append(extra, domain.FieldError{Field: "type", Reason: "debe ser time o dow"})
+	}
+
+	f, ok := h.parseFilters(w, r, extra...)
+	if !ok {
+		return
+	}
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		dataset := domain.ExportHabitsTime
+		if hType == "dow" {
+			dataset = domain.ExportHabitsDow
+		}
+		h.writeExport(w, r, format, dataset, f)
+		return
+	}
+	res, err := h.service.GetHabitAnalysis(r.Context(), hType, f)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	json.NewEncoder(w).Encode(res)
 }
 
 func (h *SpotifyHandler) GetEvolution(w http.ResponseWriter, r *http.Request) {
-	res, err := h.service.GetGlobalEvolution(r.Context(), parseSpotifyFilters(r))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	split, extra := parseSplit(r)
+	f, ok := h.parseFilters(w, r, extra...)
+	if !ok {
 		return
 	}
-	json.NewEncoder(w).Encode(res)
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		if split {
+			writeError(w, r, domain.NewValidationError("split no está disponible al exportar"))
+			return
+		}
+		h.writeExport(w, r, format, domain.ExportEvolution, f)
+		return
+	}
+	respond(w, r, f, split, h.service.GetGlobalEvolution)
 }
 
 func (h *SpotifyHandler) GetYearly(w http.ResponseWriter, r *http.Request) {
-	res, err := h.service.GetYearlyStats(r.Context(), parseSpotifyFilters(r))
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		h.writeExport(w, r, format, domain.ExportYearly, f)
+		return
+	}
+	res, err := h.service.GetYearlyStats(r.Context(), f)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	json.NewEncoder(w).Encode(res)
 }
 
-func (h *SpotifyHandler) SearchRanking(w http.ResponseWriter, r *http.Request) {
-	f := parseSpotifyFilters(r)
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+// searchRank resuelve la búsqueda de ranking común a v1 y v2. Si retorna false ya se respondió el error
+func (h *SpotifyHandler) searchRank(w http.ResponseWriter, r *http.Request) (domain.RankSearchResultDTO, bool) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return domain.RankSearchResultDTO{}, false
+	}
 	target := domain.ArtistTrackFilters{
 		Artist: r.URL.Query().Get("target_artist"),
 		Track:  r.URL.Query().Get("target_track"),
 	}
-	res, err := h.service.SearchRankedItem(r.Context(), f, target, limit)
+	res, err := h.service.SearchRankedItem(r.Context(), f, target, f.Limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return res, false
+	}
+	return res, true
+}
+
+// SearchRanking (v1) responde directamente la lista de canciones o de artistas
+func (h *SpotifyHandler) SearchRanking(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.searchRank(w, r)
+	if !ok {
+		return
+	}
+	if res.Type == "song" {
+		json.NewEncoder(w).Encode(res.Songs)
+		return
+	}
+	json.NewEncoder(w).Encode(res.Artists)
+}
+
+func (h *SpotifyHandler) GetDiversity(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetDiversity(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	json.NewEncoder(w).Encode(res)
 }
 
-func (h *SpotifyHandler) GetWrapped(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+func (h *SpotifyHandler) GetBinges(w http.ResponseWriter, r *http.Request) {
+	p := newParamParser(r.URL.Query())
+	// type puede ser "track" o "artist"
+	b := domain.BingeFilters{Type: p.q.Get("type")}
+	if b.Type != "" && b.Type != "track" && b.Type != "artist" {
+		p.fail("type", "debe ser track o artist")
+	}
+	// Fuera de rango es 400 en modo estricto; en el permisivo BingeFilters.Clean lo acota
+	if n, ok := p.int("min_plays"); ok {
+		if n < domain.MinBingePlays {
+			p.fail("min_plays", fmt.Sprintf("debe ser al menos %d", domain.MinBingePlays))
+		}
+		b.MinPlays = n
+	}
+	if n, ok := p.int("max_gap_minutes"); ok {
+		if n < 1 || n > domain.MaxBingeGapMinutes {
+			p.fail("max_gap_minutes", fmt.Sprintf("debe estar entre 1 y %d", domain.MaxBingeGapMinutes))
+		}
+		b.MaxGapMinutes = n
+	}
+
+	f, ok := h.parseFilters(w, r, p.errs...)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetBinges(r.Context(), f, b)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *SpotifyHandler) GetCountries(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetCountryAnalytics(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *SpotifyHandler) GetPlatforms(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetPlatformAnalytics(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *SpotifyHandler) GetSources(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetListeningSources(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *SpotifyHandler) GetOffline(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetOfflineStats(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *SpotifyHandler) GetIncognito(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.GetIncognitoStats(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// wrappedQuery es el periodo pedido en year, month y season
+type wrappedQuery struct {
+	year   int
+	month  int // 0 si no viene
+	season domain.Season
+}
+
+// parseWrappedQuery valida year y month y lee los filtros base (el periodo lo define el wrapped).
+// Si retorna false la respuesta de error ya fue escrita
+func (h *SpotifyHandler) parseWrappedQuery(w http.ResponseWriter, r *http.Request) (wrappedQuery, domain.SpotifyFilters, bool) {
 	q := r.URL.Query()
 
 	// 1. Validación de Año (Requerido para todos los Wrappeds)
 	yearStr := q.Get("year")
 	if yearStr == "" {
-		http.Error(w, "El parámetro 'year' es obligatorio", http.StatusBadRequest)
-		return
+		writeError(w, r, domain.NewValidationError("El parámetro 'year' es obligatorio"))
+		return wrappedQuery{}, domain.SpotifyFilters{}, false
 	}
 	year, err := strconv.Atoi(yearStr)
 	if err != nil || year < 2010 || year > time.Now().Year()+1 {
-		http.Error(w, "Año inválido", http.StatusBadRequest)
-		return
+		writeError(w, r, domain.NewValidationError("Año inválido"))
+		return wrappedQuery{}, domain.SpotifyFilters{}, false
 	}
 
-	var res interface{}
-	var svcErr error
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return wrappedQuery{}, domain.SpotifyFilters{}, false
+	}
+
+	wq := wrappedQuery{year: year, season: domain.Season(strings.ToLower(q.Get("season")))}
+	if monthStr := q.Get("month"); monthStr != "" && wq.season == "" {
+		if wq.month, err = strconv.Atoi(monthStr); err != nil {
+			writeError(w, r, domain.NewValidationError("El mes debe ser un número"))
+			return wrappedQuery{}, domain.SpotifyFilters{}, false
+		}
+		// Se valida aquí porque month=0 significaría "sin mes" para el servicio
+		if wq.month < 1 || wq.month > 12 {
+			writeError(w, r, domain.NewValidationError("el mes %d no es válido (debe ser 1-12)", wq.month))
+			return wrappedQuery{}, domain.SpotifyFilters{}, false
+		}
+	}
+	return wq, f, true
+}
+
+// resolveWrapped valida year, month y season y obtiene el wrapped correspondiente.
+// Si retorna false la respuesta de error ya fue escrita
+func (h *SpotifyHandler) resolveWrapped(w http.ResponseWriter, r *http.Request) (domain.WrappedDTO, bool) {
+	wq, f, ok := h.parseWrappedQuery(w, r)
+	if !ok {
+		return domain.WrappedDTO{}, false
+	}
+	ctx := r.Context()
 
 	// 2. Lógica de selección de Wrapped
-	season := q.Get("season")
-	monthStr := q.Get("month")
-
-	if season != "" {
-		// Caso Estacional
-		res, svcErr = h.service.GetSeasonalWrapped(ctx, year, domain.Season(strings.ToLower(season)))
-	} else if monthStr != "" {
-		// Caso Mensual
-		month, err := strconv.Atoi(monthStr)
+	var res domain.WrappedDTO
+	var err error
+	switch {
+	case wq.season != "":
+		res, err = h.service.GetSeasonalWrapped(ctx, wq.year, wq.season, f)
+	case wq.month != 0:
+		res, err = h.service.GetMonthlyWrapped(ctx, wq.year, wq.month, f)
+	default:
+		res, err = h.service.GetYearlyWrapped(ctx, wq.year, f)
+	}
+
+	// 3. Manejo de errores del Servicio
+	if err != nil {
+		writeError(w, r, err)
+		return domain.WrappedDTO{}, false
+	}
+	return res, true
+}
+
+// GetWrapped (v1) responde solo el top de canciones del periodo, con una única consulta
+func (h *SpotifyHandler) GetWrapped(w http.ResponseWriter, r *http.Request) {
+	wq, f, ok := h.parseWrappedQuery(w, r)
+	if !ok {
+		return
+	}
+	songs, err := h.service.GetWrappedTopSongs(r.Context(), wq.year, wq.month, wq.season, f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Respuesta Exitosa (El middleware JSONResponse se encarga del header)
+	json.NewEncoder(w).Encode(songs)
+}
+
+// GetPlays lista reproducciones individuales con paginación por cursor (next_cursor -> ?cursor=).
+// Acepta format= para exportar; sin limit la exportación recorre todo el historial filtrado
+func (h *SpotifyHandler) GetPlays(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var page domain.PlayPage
+
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	// Un cursor inválido se rechaza siempre: ignorarlo reiniciaría el recorrido sin aviso
+	if token := q.Get("cursor"); token != "" {
+		cursor, err := domain.DecodePlayCursor(token)
 		if err != nil {
-			http.Error(w, "El mes debe ser un número", http.StatusBadRequest)
+			writeError(w, r, domain.NewFieldsValidationError([]domain.FieldError{{Field: "cursor", Reason: err.Error()}}))
 			return
 		}
-		res, svcErr = h.service.GetMonthlyWrapped(ctx, year, month)
-	} else {
-		// Caso Anual por defecto
-		res, svcErr = h.service.GetYearlyWrapped(ctx, year)
+		page.After = &cursor
 	}
 
-	// 3. Manejo de errores del Servicio
-	if svcErr != nil {
-		http.Error(w, "Error al procesar el Wrapped: "+svcErr.Error(), http.StatusInternalServerError)
+	format, ok := negotiateFormat(w, r)
+	if !ok {
+		return
+	}
+	if format != export.FormatJSON {
+		page.Limit = f.Limit // 0 sin limit en la query ni en el preset: se exporta todo
+		streamExport(w, r, format, domain.ExportPlays, func(ctx context.Context, fn func(domain.ExportRow) error) error {
+			return h.service.ExportPlays(ctx, f, page, fn)
+		})
+		return
+	}
+
+	res, err := h.service.GetPlays(r.Context(), f, page)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	json.NewEncoder(w).Encode(res)
+}
 
-	// 4. Respuesta Exitosa (El middleware JSONResponse se encarga del header)
-	if err := json.NewEncoder(w).Encode(res); err != nil {
-		http.Error(w, "Error al codificar la respuesta", http.StatusInternalServerError)
+// GetSearch atiende el autocompletado: ?q= es obligatorio, los filtros comunes acotan el historial
+func (h *SpotifyHandler) GetSearch(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.service.Search(r.Context(), r.URL.Query().Get("q"), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetCompareUsers compara los gustos de dos usuarios (?a=<username>&b=<username>)
+func (h *SpotifyHandler) GetCompareUsers(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.parseFilters(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	res, err := h.service.CompareUsers(r.Context(), q.Get("a"), q.Get("b"), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetWrappedCard dibuja el wrapped como tarjeta de historia (1080x1920) en PNG (por defecto) o SVG.
+// El tipo de imagen va en image= y no en format=, que es el parámetro de las exportaciones
+func (h *SpotifyHandler) GetWrappedCard(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var errs []domain.FieldError
+	image := q.Get("image")
+	if image == "" {
+		image = "png"
+	}
+	if image != "png" && image != "svg" {
+		errs = append(errs, domain.FieldError{Field: "image", Reason: "debe ser png o svg"})
+	}
+	themeName := q.Get("theme")
+	if themeName == "" {
+		themeName = card.DefaultTheme
+	}
+	theme, ok := card.LookupTheme(themeName)
+	if !ok {
+		errs = append(errs, domain.FieldError{Field: "theme", Reason: "debe ser uno de " + strings.Join(card.ThemeNames(), ", ")})
+	}
+	if len(errs) > 0 {
+		writeError(w, r, domain.NewFieldsValidationError(errs))
+		return
+	}
+
+	res, ok := h.resolveWrapped(w, r)
+	if !ok {
+		return
+	}
+
+	// Se dibuja completo antes de responder, así un error todavía puede informarse como problem document
+	var buf bytes.Buffer
+	var err error
+	contentType := "image/png"
+	if image == "svg" {
+		contentType = "image/svg+xml"
+		err = card.RenderSVG(&buf, res, theme)
+	} else {
+		err = card.RenderPNG(&buf, res, theme)
+	}
+	if err != nil {
+		writeError(w, r, domain.NewInternalError(err, "No se pudo generar la tarjeta"))
+		return
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	buf.WriteTo(w)
 }
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/config"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+type wrappedStub struct{ stubSpotifyService }
+
+func (wrappedStub) GetYearlyWrapped(ctx context.Context, year int, f domain.SpotifyFilters) (domain.WrappedDTO, error) {
+	return domain.WrappedDTO{Period: domain.WrappedPeriodDTO{Type: domain.WrappedYear, Year: year}}, nil
+}
+
+func TestWrappedCardImageParam(t *testing.T) {
+	router := NewRouter(wrappedStub{}, nil, stubExclusionService{}, stubShareService{}, nil, config.CORSConfig{AllowedOrigins: []string{"*"}})
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantType   string
+	}{
+		{"year=2024", http.StatusOK, "image/png"},
+		{"year=2024&image=svg", http.StatusOK, "image/svg+xml"},
+		{"year=2024&image=svg&theme=sunset", http.StatusOK, "image/svg+xml"},
+		// format= es de las exportaciones: la tarjeta lo ignora
+		{"year=2024&format=csv", http.StatusOK, "image/png"},
+		{"year=2024&image=gif", http.StatusBadRequest, "application/problem+json"},
+		{"year=2024&theme=neon", http.StatusBadRequest, "application/problem+json"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/spotify/wrapped/card?"+tt.query, nil))
+		if rec.Code != tt.wantStatus || !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.wantType) {
+			t.Errorf("%s: status %d %q, se esperaba %d %q", tt.query, rec.Code, rec.Header().Get("Content-Type"), tt.wantStatus, tt.wantType)
+		}
+		if tt.wantStatus == http.StatusBadRequest && strings.Contains(tt.query, "image=") && !strings.Contains(rec.Body.String(), `"field":"image"`) {
+			t.Errorf("%s: el error no indica el campo image: %s", tt.query, rec.Body)
+		}
+	}
+}
+
+// bingeStub registra los parámetros de racha que recibe el servicio
+type bingeStub struct {
+	stubSpotifyService
+	got *domain.BingeFilters
+}
+
+func (s bingeStub) GetBinges(ctx context.Context, f domain.SpotifyFilters, b domain.BingeFilters) (domain.Pagination[domain.BingeDTO], error) {
+	*s.got = b
+	return domain.Pagination[domain.BingeDTO]{Data: []domain.BingeDTO{}}, nil
+}
+
+func TestBingeParams(t *testing.T) {
+	var got domain.BingeFilters
+	router := NewRouter(bingeStub{got: &got}, nil, stubExclusionService{}, stubShareService{}, nil, config.CORSConfig{AllowedOrigins: []string{"*"}})
+	tests := []struct {
+		path       string
+		wantStatus int
+		want       domain.BingeFilters
+	}{
+		{"/api/v2/spotify/binges?min_plays=4&max_gap_minutes=1440", http.StatusOK, domain.BingeFilters{MinPlays: 4, MaxGapMinutes: 1440}},
+		{"/api/v2/spotify/binges?min_plays=1", http.StatusBadRequest, domain.BingeFilters{}},
+		{"/api/v2/spotify/binges?max_gap_minutes=0", http.StatusBadRequest, domain.BingeFilters{}},
+		{"/api/v2/spotify/binges?max_gap_minutes=1441", http.StatusBadRequest, domain.BingeFilters{}},
+		{"/api/v1/spotify/binges?min_plays=1&strict=true", http.StatusBadRequest, domain.BingeFilters{}},
+		// v1 sin strict es permisivo: el valor llega al servicio, que lo acota con Clean
+		{"/api/v1/spotify/binges?min_plays=1&max_gap_minutes=5000", http.StatusOK, domain.BingeFilters{MinPlays: 1, MaxGapMinutes: 5000}},
+	}
+	for _, tt := range tests {
+		got = domain.BingeFilters{}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, se esperaba %d", tt.path, rec.Code, tt.wantStatus)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: el servicio recibió %+v, se esperaba %+v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handlers de /api/v2 cuyo contrato difiere de /api/v1.
+// El resto de las rutas comparte handler en ambas versiones
+
+// SearchRankingV2 responde domain.RankSearchResultDTO, indicando si la lista es de canciones o artistas
+func (h *SpotifyHandler) SearchRankingV2(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.searchRank(w, r)
+	if !ok {
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetWrappedV2 responde el domain.WrappedDTO completo: periodo, stats, tops y momentos del día
+func (h *SpotifyHandler) GetWrappedV2(w http.ResponseWriter, r *http.Request) {
+	res, ok := h.resolveWrapped(w, r)
+	if !ok {
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Entrada del export extendido de Spotify (Streaming_History_Audio_*.json)
+type exportEntry struct {
+	TS          time.Time `json:"ts"` // UTC, fin de la reproducción
+	Platform    string    `json:"platform"`
+	MsPlayed    int       `json:"ms_played"`
+	ConnCountry string    `json:"conn_country"`
+	TrackName   *string   `json:"master_metadata_track_name"`
+	ArtistName  *string   `json:"master_metadata_album_artist_name"`
+	AlbumName   *string   `json:"master_metadata_album_album_name"`
+	TrackURI    *string   `json:"spotify_track_uri"`
+	EpisodeURI  *string   `json:"spotify_episode_uri"`
+	ReasonStart string    `json:"reason_start"`
+	Shuffle     *bool     `json:"shuffle"`
+
+	Offline          *bool  `json:"offline"`
+	OfflineTimestamp *int64 `json:"offline_timestamp"` // Epoch en segundos (exports antiguos) o milisegundos
+	IncognitoMode    *bool  `json:"incognito_mode"`
+}
+
+// ParseStreamingHistory lee un archivo del export y lo convierte en registros listos para insertar.
+// ts se guarda como hora local de Chile, igual que el resto de la tabla (TIMESTAMP sin zona)
+func ParseStreamingHistory(r io.Reader) ([]domain.SpotifyRecord, error) {
+	var entries []exportEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("error al leer el export: %v", err)
+	}
+
+	loc, _ := time.LoadLocation("America/Santiago")
+
+	records := make([]domain.SpotifyRecord, 0, len(entries))
+	for _, e := range entries {
+		rec := domain.SpotifyRecord{
+			TS:               e.TS.In(loc),
+			Platform:         e.Platform,
+			MsPlayed:         e.MsPlayed,
+			ConnCountry:      e.ConnCountry,
+			TrackName:        deref(e.TrackName),
+			ArtistName:       deref(e.ArtistName),
+			AlbumName:        deref(e.AlbumName),
+			SpotifyURI:       deref(e.TrackURI),
+			Shuffle:          e.Shuffle,
+			ReasonStart:      e.ReasonStart,
+			Offline:          e.Offline,
+			OfflineTimestamp: parseEpoch(e.OfflineTimestamp, loc),
+			IncognitoMode:    e.IncognitoMode,
+		}
+		// Los podcasts no traen track URI, se conservan con su URI de episodio
+		if rec.SpotifyURI == "" {
+			rec.SpotifyURI = deref(e.EpisodeURI)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// parseEpoch interpreta offline_timestamp, 0 o null significan que no hubo modo offline
+func parseEpoch(epoch *int64, loc *time.Location) *time.Time {
+	if epoch == nil || *epoch <= 0 {
+		return nil
+	}
+	var t time.Time
+	if *epoch > 1e12 {
+		t = time.UnixMilli(*epoch)
+	} else {
+		t = time.Unix(*epoch, 0)
+	}
+	t = t.In(loc)
+	return &t
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
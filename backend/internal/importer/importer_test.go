@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEpoch(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Santiago")
+	epoch := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name  string
+		epoch *int64
+		want  time.Time // Cero si se espera nil
+	}{
+		{name: "null", epoch: nil},
+		{name: "cero", epoch: epoch(0)},
+		{name: "negativo", epoch: epoch(-1)},
+		{name: "segundos", epoch: epoch(1672531200), want: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "milisegundos", epoch: epoch(1672531200123), want: time.Date(2023, 1, 1, 0, 0, 0, 123e6, time.UTC)},
+		// 1e12 es el límite: como segundos sería el año 33658, se interpreta como milisegundos desde 2001
+		{name: "límite 1e12 en segundos", epoch: epoch(1e12), want: time.Unix(1e12, 0)},
+		{name: "sobre el límite", epoch: epoch(1e12 + 1), want: time.UnixMilli(1e12 + 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseEpoch(tt.epoch, loc)
+			if tt.want.IsZero() {
+				if got != nil {
+					t.Fatalf("se esperaba nil, se obtuvo %v", got)
+				}
+				return
+			}
+			if got == nil || !got.Equal(tt.want) {
+				t.Fatalf("parseEpoch = %v, se esperaba %v", got, tt.want)
+			}
+			if got.Location() != loc {
+				t.Errorf("zona %v, se esperaba hora de Chile", got.Location())
+			}
+		})
+	}
+}
+
+func TestParseStreamingHistory(t *testing.T) {
+	const export = `[
+	{"ts": "2023-01-01T03:00:00Z", "platform": "Android OS 12 API 31", "ms_played": 215000, "conn_country": "CL",
+	 "master_metadata_track_name": "Tití Me Preguntó", "master_metadata_album_artist_name": "Bad Bunny",
+	 "master_metadata_album_album_name": "Un Verano Sin Ti", "spotify_track_uri": "spotify:track:1",
+	 "spotify_episode_uri": null, "reason_start": "clickrow", "shuffle": true,
+	 "offline": true, "offline_timestamp": 1672541000, "incognito_mode": false},
+	{"ts": "2023-01-02T15:30:00Z", "platform": "iOS 16.1 (iPhone14,2)", "ms_played": 1800000, "conn_country": "AR",
+	 "master_metadata_track_name": null, "master_metadata_album_artist_name": null,
+	 "master_metadata_album_album_name": null, "spotify_track_uri": null,
+	 "spotify_episode_uri": "spotify:episode:9", "reason_start": "trackdone", "shuffle": null,
+	 "offline": null, "offline_timestamp": 0, "incognito_mode": null}
+]`
+	records, err := ParseStreamingHistory(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d registros, se esperaban 2", len(records))
+	}
+
+	song, episode := records[0], records[1]
+	// 03:00 UTC es medianoche en Chile (verano, UTC-3)
+	if got := song.TS.Format("2006-01-02 15:04"); got != "2023-01-01 00:00" {
+		t.Errorf("ts local %s", got)
+	}
+	if song.TrackName != "Tití Me Preguntó" || song.ArtistName != "Bad Bunny" || song.SpotifyURI != "spotify:track:1" || song.MsPlayed != 215000 {
+		t.Errorf("canción: %+v", song)
+	}
+	if song.Shuffle == nil || !*song.Shuffle || song.Offline == nil || !*song.Offline || song.OfflineTimestamp == nil || song.OfflineTimestamp.Unix() != 1672541000 {
+		t.Errorf("shuffle/offline: %+v", song)
+	}
+	if song.IncognitoMode == nil || *song.IncognitoMode {
+		t.Errorf("incognito_mode false se perdió: %v", song.IncognitoMode)
+	}
+
+	// Los podcasts conservan la URI del episodio; los null quedan vacíos o nil
+	if episode.SpotifyURI != "spotify:episode:9" || episode.TrackName != "" || episode.ArtistName != "" {
+		t.Errorf("episodio: %+v", episode)
+	}
+	if episode.Shuffle != nil || episode.Offline != nil || episode.OfflineTimestamp != nil || episode.IncognitoMode != nil {
+		t.Errorf("campos null del episodio: %+v", episode)
+	}
+
+	if _, err := ParseStreamingHistory(strings.NewReader(`{"ts": "no es un arreglo"}`)); err == nil {
+		t.Errorf("se aceptó un export que no es un arreglo")
+	}
+	if _, err := ParseStreamingHistory(strings.NewReader(`[{"ts": "ayer"}]`)); err == nil {
+		t.Errorf("se aceptó un ts inválido")
+	}
+}
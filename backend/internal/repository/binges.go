@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// GetBinges detecta rachas de reproducciones consecutivas (gaps and islands sobre el índice de ts).
+// Una racha se corta cuando cambia la canción/artista o cuando la pausa entre reproducciones supera MaxGapMinutes
+func (r *spotifyRepo) GetBinges(ctx context.Context, f domain.SpotifyFilters, b domain.BingeFilters) ([]domain.BingeDTO, int, error) {
+	where, args := buildWhereClause(ctx, f)
+
+	changed := "artist_name IS DISTINCT FROM prev_artist"
+	groupCols := "artist_name"
+	trackCol := "''"
+	if b.Type == "track" {
+		changed = "track_name IS DISTINCT FROM prev_track OR " + changed
+		groupCols = "track_name, artist_name"
+		trackCol = "track_name"
+	}
+
+	// ts marca el fin de la reproducción, la pausa real descuenta lo escuchado
+	cte := fmt.Sprintf(`
+		WITH ordered AS (
+			SELECT
+				id, ts, ms_played, track_name, artist_name,
+				LAG(ts) OVER w AS prev_ts,
+				LAG(track_name) OVER w AS prev_track,
+				LAG(artist_name) OVER w AS prev_artist
+			FROM spotify_history
+			%s
+			WINDOW w AS (ORDER BY ts, id)
+		),
+		streaks AS (
+			SELECT *,
+				SUM(CASE
+					WHEN prev_ts IS NULL OR %s THEN 1
+					WHEN ts - prev_ts - ms_played * INTERVAL '1 millisecond' > make_interval(mins => $%d) THEN 1
+					ELSE 0
+				END) OVER (ORDER BY ts, id) AS streak_id
+			FROM ordered
+		),
+		binges AS (
+			SELECT
+				%s AS track_name,
+				artist_name,
+				MIN(ts - ms_played * INTERVAL '1 millisecond') AS started_at,
+				MAX(ts) AS ended_at,
+				COUNT(*) AS plays,
+				COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played,
+				COUNT(DISTINCT track_name) AS distinct_tracks
+			FROM streaks
+			GROUP BY streak_id, %s
+			HAVING COUNT(*) >= $%d
+		)`, where, changed, len(args)+1, trackCol, groupCols, len(args)+2)
+
+	args = append(args, b.MaxGapMinutes, b.MinPlays)
+
+	total, err := r.countRows(ctx, cte+" SELECT COUNT(*) FROM binges", args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al contar rachas: %w", err)
+	}
+
+	query := fmt.Sprintf(`%s
+		SELECT
+			RANK() OVER (ORDER BY plays DESC, minutes_played DESC) AS ranking,
+			track_name, artist_name, started_at, ended_at, plays, minutes_played, distinct_tracks
+		FROM binges
+		ORDER BY ranking, started_at
+		LIMIT $%d OFFSET $%d`, cte, len(args)+1, len(args)+2)
+
+	pagedArgs := append(args, f.Limit, f.Offset())
+	rows, err := r.db.Query(ctx, query, pagedArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var resul []domain.BingeDTO
+	for rows.Next() {
+		var d domain.BingeDTO
+		if err := rows.Scan(&d.Ranking, &d.TrackName, &d.ArtistName, &d.StartedAt, &d.EndedAt, &d.Plays, &d.MinutesPlayed, &d.DistinctTracks); err != nil {
+			return nil, 0, err
+		}
+		resul = append(resul, d)
+	}
+
+	if resul == nil {
+		resul = []domain.BingeDTO{}
+	}
+
+	return resul, total, rows.Err()
+}
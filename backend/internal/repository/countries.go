@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// País normalizado, Spotify usa ZZ cuando no lo conoce
+const countryExpr = "COALESCE(NULLIF(conn_country, ''), 'ZZ')"
+
+// GetCountryStats obtiene reproducciones, minutos y primera/última fecha por país
+func (r *spotifyRepo) GetCountryStats(ctx context.Context, f domain.SpotifyFilters) ([]domain.CountryStatsDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		SELECT
+			%s AS country,
+			COUNT(*) AS plays,
+			COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played,
+			MIN(ts) AS first_played,
+			MAX(ts) AS last_played
+		FROM spotify_history
+		%s
+		GROUP BY country
+		ORDER BY minutes_played DESC`, countryExpr, where)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resul []domain.CountryStatsDTO
+	for rows.Next() {
+		var d domain.CountryStatsDTO
+		if err := rows.Scan(&d.Country, &d.Plays, &d.MinutesPlayed, &d.FirstPlayed, &d.LastPlayed); err != nil {
+			return nil, err
+		}
+		resul = append(resul, d)
+	}
+	return resul, rows.Err()
+}
+
+// GetTopArtistsByCountry obtiene los primeros `limit` artistas de cada país
+func (r *spotifyRepo) GetTopArtistsByCountry(ctx context.Context, f domain.SpotifyFilters, limit int) (map[string][]domain.ArtistRankingDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		WITH ranking_pais AS (
+			SELECT
+				%s AS country,
+				RANK() OVER (PARTITION BY %s ORDER BY %s) AS ranking,
+				artist_name,
+				%s
+			FROM spotify_history
+			%s
+			GROUP BY country, artist_name
+		)
+		SELECT * FROM ranking_pais
+		WHERE ranking <= $%d
+		ORDER BY country, ranking, artist_name`, countryExpr, countryExpr, rankingOrder(f), rankingMetricColumns, where, len(args)+1)
+
+	args = append(args, limit)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resul := map[string][]domain.ArtistRankingDTO{}
+	for rows.Next() {
+		var country string
+		var d domain.ArtistRankingDTO
+		if err := rows.Scan(append([]interface{}{&country, &d.Ranking, &d.ArtistName}, rankingMetricDest(&d.RankingMetrics)...)...); err != nil {
+			return nil, err
+		}
+		resul[country] = append(resul[country], d)
+	}
+	return resul, rows.Err()
+}
+
+// GetCountryTimeline agrupa reproducciones consecutivas desde el mismo país.
+// Cada cambio de país abre un tramo nuevo, lo que permite reconstruir los viajes
+func (r *spotifyRepo) GetCountryTimeline(ctx context.Context, f domain.SpotifyFilters) ([]domain.CountryTripDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		WITH ordered AS (
+			SELECT
+				id, ts, ms_played,
+				%s AS country,
+				LAG(%s) OVER (ORDER BY ts, id) AS prev_country
+			FROM spotify_history
+			%s
+		),
+		tramos AS (
+			SELECT *,
+				SUM(CASE WHEN country IS DISTINCT FROM prev_country THEN 1 ELSE 0 END) OVER (ORDER BY ts, id) AS tramo_id
+			FROM ordered
+		)
+		SELECT
+			country,
+			MIN(ts) AS started_at,
+			MAX(ts) AS ended_at,
+			COUNT(DISTINCT ts::date) AS days,
+			COUNT(*) AS plays,
+			COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played
+		FROM tramos
+		GROUP BY tramo_id, country
+		ORDER BY started_at`, countryExpr, countryExpr, where)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resul []domain.CountryTripDTO
+	for rows.Next() {
+		var d domain.CountryTripDTO
+		if err := rows.Scan(&d.Country, &d.StartedAt, &d.EndedAt, &d.Days, &d.Plays, &d.MinutesPlayed); err != nil {
+			return nil, err
+		}
+		resul = append(resul, d)
+	}
+	return resul, rows.Err()
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Columnas que identifican un item según la dimensión
+func diversityGroupColumns(dim domain.DiversityDimension) string {
+	if dim == domain.DiversityByTrack {
+		return "track_name, artist_name"
+	}
+	return "artist_name"
+}
+
+// queryDiversity calcula entropía, Gini y concentración por periodo.
+// periodExpr define la partición: constante para el total, TO_CHAR(ts, 'YYYY-MM') para la evolución mensual
+func (r *spotifyRepo) queryDiversity(ctx context.Context, f domain.SpotifyFilters, dim domain.DiversityDimension, periodExpr string) (map[string]domain.DiversityMetricsDTO, []string, error) {
+	where, args := buildWhereClause(ctx, f)
+	cols := diversityGroupColumns(dim)
+
+	// Gini con valores ordenados ascendentemente: (2 * Σ i·x_i) / (n · Σ x) - (n + 1) / n
+	query := fmt.Sprintf(`
+		WITH counts AS (
+			SELECT period, COUNT(*)::float8 AS plays
+			FROM (
+				SELECT %s AS period, %s
+				FROM spotify_history
+				%s
+			) AS base
+			GROUP BY period, %s
+		),
+		ranked AS (
+			SELECT
+				period,
+				plays,
+				ROW_NUMBER() OVER (PARTITION BY period ORDER BY plays DESC) AS pos_desc,
+				ROW_NUMBER() OVER (PARTITION BY period ORDER BY plays ASC) AS pos_asc,
+				SUM(plays) OVER (PARTITION BY period) AS total,
+				COUNT(*) OVER (PARTITION BY period) AS n
+			FROM counts
+		)
+		SELECT
+			period,
+			COUNT(*)::int AS unique_items,
+			ROUND(COALESCE(-SUM(plays / total * LN(plays / total)), 0)::numeric, 4) AS entropy,
+			ROUND(COALESCE(2 * SUM(pos_asc * plays) / (MAX(n) * MAX(total)) - (MAX(n) + 1)::float8 / MAX(n), 0)::numeric, 4) AS gini,
+			ROUND(COALESCE(SUM(plays) FILTER (WHERE pos_desc <= 1) / MAX(total), 0)::numeric, 4) AS top_1_share,
+			ROUND(COALESCE(SUM(plays) FILTER (WHERE pos_desc <= 10) / MAX(total), 0)::numeric, 4) AS top_10_share,
+			ROUND(COALESCE(SUM(plays) FILTER (WHERE pos_desc <= 100) / MAX(total), 0)::numeric, 4) AS top_100_share
+		FROM ranked
+		GROUP BY period
+		ORDER BY period`, periodExpr, cols, where, cols)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	res := map[string]domain.DiversityMetricsDTO{}
+	var periods []string
+	for rows.Next() {
+		var period string
+		var d domain.DiversityMetricsDTO
+		if err := rows.Scan(&period, &d.UniqueItems, &d.Entropy, &d.Gini, &d.Top1Share, &d.Top10Share, &d.Top100Share); err != nil {
+			return nil, nil, err
+		}
+		// Entropía normalizada: H / ln(n). Con un solo item no hay diversidad posible
+		if d.UniqueItems > 1 {
+			d.NormalizedEntropy = math.Round(d.Entropy/math.Log(float64(d.UniqueItems))*10000) / 10000
+		}
+		res[period] = d
+		periods = append(periods, period)
+	}
+	return res, periods, rows.Err()
+}
+
+// GetDiversityMetrics calcula las métricas sobre todo el rango filtrado
+func (r *spotifyRepo) GetDiversityMetrics(ctx context.Context, f domain.SpotifyFilters, dim domain.DiversityDimension) (domain.DiversityMetricsDTO, error) {
+	res, _, err := r.queryDiversity(ctx, f, dim, "'total'::text")
+	if err != nil {
+		return domain.DiversityMetricsDTO{}, err
+	}
+	return res["total"], nil
+}
+
+// GetMonthlyDiversity calcula las métricas mes a mes (YYYY-MM), ordenadas cronológicamente
+func (r *spotifyRepo) GetMonthlyDiversity(ctx context.Context, f domain.SpotifyFilters, dim domain.DiversityDimension) ([]domain.MonthlyDiversityDTO, error) {
+	res, periods, err := r.queryDiversity(ctx, f, dim, "TO_CHAR(ts, 'YYYY-MM')")
+	if err != nil {
+		return nil, err
+	}
+
+	resul := make([]domain.MonthlyDiversityDTO, 0, len(periods))
+	for _, p := range periods {
+		m := domain.MonthlyDiversityDTO{YearMonth: p}
+		if dim == domain.DiversityByTrack {
+			m.Tracks = res[p]
+		} else {
+			m.Artists = res[p]
+		}
+		resul = append(resul, m)
+	}
+	return resul, nil
+}
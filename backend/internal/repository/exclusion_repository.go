@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExclusionRepository administra la lista de exclusión (tabla excluded_entities) del usuario del contexto
+type ExclusionRepository interface {
+	List(ctx context.Context) ([]domain.ExcludedEntity, error)
+	GetByID(ctx context.Context, id int) (domain.ExcludedEntity, error)
+	Create(ctx context.Context, in domain.ExcludedEntityInput) (domain.ExcludedEntity, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type exclusionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExclusionRepository(db *pgxpool.Pool) ExclusionRepository {
+	return &exclusionRepo{db: db}
+}
+
+// exclusionClause descarta las reproducciones que calzan con alguna entidad excluida.
+// Las columnas se califican con la tabla para no confundirlas con las de excluded_entities
+const exclusionClause = `NOT EXISTS (
+			SELECT 1 FROM excluded_entities e
+			WHERE e.user_id = spotify_history.user_id
+			  AND ((e.kind = 'artist' AND lower(e.value) = lower(spotify_history.artist_name))
+			   OR (e.kind = 'track' AND lower(e.value) = lower(spotify_history.track_name)
+			       AND (e.artist_name = '' OR lower(e.artist_name) = lower(spotify_history.artist_name)))
+			   OR (e.kind = 'uri' AND e.value = spotify_history.spotify_uri)))`
+
+const exclusionColumns = "id, kind, value, artist_name, reason, created_at"
+
+func (r *exclusionRepo) List(ctx context.Context) ([]domain.ExcludedEntity, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+exclusionColumns+" FROM excluded_entities WHERE user_id = $1 ORDER BY kind, lower(value)",
+		domain.UserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error al listar exclusiones: %w", err)
+	}
+	defer rows.Close()
+
+	res := []domain.ExcludedEntity{}
+	for rows.Next() {
+		var e domain.ExcludedEntity
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Value, &e.ArtistName, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+func (r *exclusionRepo) GetByID(ctx context.Context, id int) (domain.ExcludedEntity, error) {
+	var e domain.ExcludedEntity
+	err := r.db.QueryRow(ctx, "SELECT "+exclusionColumns+" FROM excluded_entities WHERE id = $1 AND user_id = $2",
+		id, domain.UserIDFromContext(ctx)).
+		Scan(&e.ID, &e.Kind, &e.Value, &e.ArtistName, &e.Reason, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return e, ErrNotFound
+	}
+	return e, err
+}
+
+func (r *exclusionRepo) Create(ctx context.Context, in domain.ExcludedEntityInput) (domain.ExcludedEntity, error) {
+	var e domain.ExcludedEntity
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO excluded_entities (user_id, kind, value, artist_name, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+exclusionColumns, domain.UserIDFromContext(ctx), in.Kind, in.Value, in.ArtistName, in.Reason).
+		Scan(&e.ID, &e.Kind, &e.Value, &e.ArtistName, &e.Reason, &e.CreatedAt)
+	return e, uniqueViolation(err)
+}
+
+func (r *exclusionRepo) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM excluded_entities WHERE id = $1 AND user_id = $2", id, domain.UserIDFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error al eliminar exclusión: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
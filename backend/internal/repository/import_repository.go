@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ImportRepository carga el historial exportado en spotify_history, asignado a un usuario
+type ImportRepository interface {
+	// InsertRecords retorna cuántos registros se insertaron: los que ya estaban se omiten
+	InsertRecords(ctx context.Context, userID int, records []domain.SpotifyRecord) (int64, error)
+}
+
+type importRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewImportRepository(db *pgxpool.Pool) ImportRepository {
+	return &importRepo{db: db}
+}
+
+// Columnas que se cargan con COPY (id es SERIAL)
+var importColumns = []string{
+	"user_id", "ts", "platform", "ms_played", "conn_country", "track_name", "artist_name",
+	"album_name", "spotify_uri", "shuffle", "reason_start", "offline", "offline_timestamp",
+	"incognito_mode",
+}
+
+// InsertRecords usa COPY, mucho más rápido que INSERT fila a fila para exports de cientos de miles de registros.
+// COPY no admite ON CONFLICT, por eso se copia a una tabla temporal y desde ahí se insertan solo las
+// reproducciones nuevas (índice único de 010_history_dedupe.sql): reimportar un archivo no duplica nada
+func (r *importRepo) InsertRecords(ctx context.Context, userID int, records []domain.SpotifyRecord) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar la importación: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, createStagingSQL); err != nil {
+		return 0, fmt.Errorf("error al crear la tabla temporal: %w", err)
+	}
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"import_staging"}, importColumns,
+		pgx.CopyFromSlice(len(records), func(i int) ([]interface{}, error) {
+			rec := records[i]
+			return []interface{}{
+				userID, rec.TS, rec.Platform, rec.MsPlayed, rec.ConnCountry, nullIfEmpty(rec.TrackName),
+				nullIfEmpty(rec.ArtistName), nullIfEmpty(rec.AlbumName), nullIfEmpty(rec.SpotifyURI),
+				rec.Shuffle, nullIfEmpty(rec.ReasonStart), rec.Offline, rec.OfflineTimestamp,
+				rec.IncognitoMode,
+			}, nil
+		}))
+	if err != nil {
+		return 0, fmt.Errorf("error al copiar los registros: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, insertNewPlaysSQL)
+	if err != nil {
+		return 0, fmt.Errorf("error al insertar los registros: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error al confirmar la importación: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+var (
+	createStagingSQL = "CREATE TEMP TABLE import_staging ON COMMIT DROP AS SELECT " +
+		strings.Join(importColumns, ", ") + " FROM spotify_history WITH NO DATA"
+
+	// DO NOTHING también descarta los duplicados dentro del mismo archivo
+	insertNewPlaysSQL = "INSERT INTO spotify_history (" + strings.Join(importColumns, ", ") + ") SELECT " +
+		strings.Join(importColumns, ", ") + " FROM import_staging ON CONFLICT DO NOTHING"
+)
+
+// Los campos vacíos del export se guardan como NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// platformFamilyExpr traduce domain.PlatformRules a un CASE SQL, así la normalización
+// es la misma en Go y en las consultas (agrupación y filtro platform)
+var platformFamilyExpr = buildPlatformFamilyExpr()
+
+func buildPlatformFamilyExpr() string {
+	var sb strings.Builder
+	sb.WriteString("(CASE")
+	for _, rule := range domain.PlatformRules {
+		conds := make([]string, 0, len(rule.Patterns))
+		for _, pattern := range rule.Patterns {
+			// POSITION evita que '_' se interprete como comodín de LIKE
+			conds = append(conds, fmt.Sprintf("POSITION('%s' IN LOWER(platform)) > 0", pattern))
+		}
+		fmt.Fprintf(&sb, " WHEN %s THEN '%s'", strings.Join(conds, " OR "), rule.Family)
+	}
+	fmt.Fprintf(&sb, " ELSE '%s' END)", domain.DeviceOther)
+	return sb.String()
+}
+
+// GetPlatformStats obtiene reproducciones y minutos por familia de dispositivo
+func (r *spotifyRepo) GetPlatformStats(ctx context.Context, f domain.SpotifyFilters) ([]domain.PlatformStatsDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		SELECT
+			%s AS family,
+			COUNT(*) AS plays,
+			COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played,
+			COALESCE(ROUND(SUM(ms_played) / NULLIF(SUM(SUM(ms_played)) OVER (), 0), 4), 0) AS share
+		FROM spotify_history
+		%s
+		GROUP BY family
+		ORDER BY minutes_played DESC`, platformFamilyExpr, where)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resul []domain.PlatformStatsDTO
+	for rows.Next() {
+		var d domain.PlatformStatsDTO
+		if err := rows.Scan(&d.Family, &d.Plays, &d.MinutesPlayed, &d.Share); err != nil {
+			return nil, err
+		}
+		resul = append(resul, d)
+	}
+	return resul, rows.Err()
+}
+
+// GetPlatformEvolution obtiene el consumo mensual de cada familia de dispositivo
+func (r *spotifyRepo) GetPlatformEvolution(ctx context.Context, f domain.SpotifyFilters) ([]domain.PlatformEvolutionDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		SELECT
+			TO_CHAR(ts, 'YYYY-MM') AS year_month,
+			%s AS family,
+			COUNT(*) AS plays,
+			COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played
+		FROM spotify_history
+		%s
+		GROUP BY year_month, family
+		ORDER BY year_month, minutes_played DESC`, platformFamilyExpr, where)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resul []domain.PlatformEvolutionDTO
+	for rows.Next() {
+		var d domain.PlatformEvolutionDTO
+		if err := rows.Scan(&d.YearMonth, &d.Family, &d.Plays, &d.MinutesPlayed); err != nil {
+			return nil, err
+		}
+		resul = append(resul, d)
+	}
+	return resul, rows.Err()
+}
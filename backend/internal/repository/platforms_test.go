@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+var (
+	whenRe     = regexp.MustCompile(`WHEN (.+?) THEN '(\w+)'`)
+	positionRe = regexp.MustCompile(`^POSITION\('([^']*)' IN LOWER\(platform\)\) > 0$`)
+)
+
+// evalPlatformCase interpreta el CASE generado como lo haría Postgres: la primera rama que coincide
+func evalPlatformCase(t *testing.T, expr, platform string) domain.DeviceFamily {
+	t.Helper()
+	for _, m := range whenRe.FindAllStringSubmatch(expr, -1) {
+		for _, cond := range strings.Split(m[1], " OR ") {
+			pos := positionRe.FindStringSubmatch(cond)
+			if pos == nil {
+				t.Fatalf("condición inesperada en el CASE: %q", cond)
+			}
+			if strings.Contains(strings.ToLower(platform), pos[1]) {
+				return domain.DeviceFamily(m[2])
+			}
+		}
+	}
+	return domain.DeviceOther
+}
+
+func TestPlatformFamilyExprMatchesNormalizePlatform(t *testing.T) {
+	if n := len(whenRe.FindAllString(platformFamilyExpr, -1)); n != len(domain.PlatformRules) {
+		t.Fatalf("el CASE tiene %d ramas, se esperaban %d", n, len(domain.PlatformRules))
+	}
+	for _, rule := range domain.PlatformRules {
+		for _, pattern := range rule.Patterns {
+			if strings.ContainsAny(pattern, `'\`) || pattern != strings.ToLower(pattern) {
+				t.Errorf("patrón %q: debe estar en minúsculas y sin comillas", pattern)
+			}
+		}
+	}
+
+	samples := []string{
+		"Android OS 12 API 31 (Google, Pixel 6)",
+		"Android OS 10 API 29 (Android Auto)",
+		"Partner android_auto Hyundai",
+		"Partner android_tv Sony BRAVIA 4K",
+		"iOS 16.1 (iPhone14,2)",
+		"tvOS 16.1 (AppleTV11,1)",
+		"web_player windows 10;chrome 108.0.0.0;desktop",
+		"web_player osx 10.15.7;safari 16.1;desktop",
+		"Windows 10 (10.0.19044; x64; AppX)",
+		"OS X 12.6.1 [arm 2]",
+		"Linux [x86-64 0]",
+		"Partner sonos_amlogic_a113 Sonos Era 100",
+		"Partner ps5 Sony PlayStation 5",
+		"not_applicable",
+		"",
+	}
+	for _, raw := range samples {
+		if got, want := evalPlatformCase(t, platformFamilyExpr, raw), domain.NormalizePlatform(raw); got != want {
+			t.Errorf("%q: el CASE SQL da %s y NormalizePlatform %s", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+const playColumns = `id, ts, COALESCE(platform, ''), ms_played, COALESCE(conn_country, ''),
+	COALESCE(track_name, ''), COALESCE(artist_name, ''), COALESCE(album_name, ''), COALESCE(spotify_uri, ''),
+	shuffle, COALESCE(reason_start, ''), offline, offline_timestamp, incognito_mode`
+
+// GetPlays lista reproducciones individuales con paginación por cursor sobre (ts, id)
+func (r *spotifyRepo) GetPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage) ([]domain.SpotifyRecord, error) {
+	plays := []domain.SpotifyRecord{}
+	err := r.StreamPlays(ctx, f, p, func(rec domain.SpotifyRecord) error {
+		plays = append(plays, rec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener reproducciones: %w", err)
+	}
+	return plays, nil
+}
+
+func (r *spotifyRepo) StreamPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage, fn func(domain.SpotifyRecord) error) error {
+	where, args := buildWhereClause(ctx, f)
+
+	// La comparación de filas (ts, id) < (x, y) aprovecha el índice idx_spotify_ts_id
+	cmp, dir := "<", "DESC"
+	if f.Order == domain.SortAsc {
+		cmp, dir = ">", "ASC"
+	}
+	if p.After != nil {
+		where += fmt.Sprintf(" AND (ts, id) %s ($%d, $%d)", cmp, len(args)+1, len(args)+2)
+		args = append(args, p.After.TS, p.After.ID)
+	}
+
+	var limit interface{} // LIMIT NULL = sin límite
+	if p.Limit > 0 {
+		limit = p.Limit
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM spotify_history
+		%s
+		ORDER BY ts %s, id %s
+		LIMIT $%d`, playColumns, where, dir, dir, len(args))
+
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.SpotifyRecord, error) {
+		var rec domain.SpotifyRecord
+		err := rows.Scan(&rec.ID, &rec.TS, &rec.Platform, &rec.MsPlayed, &rec.ConnCountry,
+			&rec.TrackName, &rec.ArtistName, &rec.AlbumName, &rec.SpotifyURI,
+			&rec.Shuffle, &rec.ReasonStart, &rec.Offline, &rec.OfflineTimestamp, &rec.IncognitoMode)
+		return rec, err
+	}, fn)
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Errores que la capa de servicio traduce a respuestas 404 y 400
+var (
+	ErrNotFound  = errors.New("registro no encontrado")
+	ErrDuplicate = errors.New("registro duplicado")
+)
+
+// PresetRepository guarda los presets de filtros (tabla filter_presets) del usuario del contexto
+type PresetRepository interface {
+	List(ctx context.Context) ([]domain.FilterPreset, error)
+	GetByID(ctx context.Context, id int) (domain.FilterPreset, error)
+	GetByName(ctx context.Context, name string) (domain.FilterPreset, error)
+	Create(ctx context.Context, in domain.FilterPresetInput) (domain.FilterPreset, error)
+	Update(ctx context.Context, id int, in domain.FilterPresetInput) (domain.FilterPreset, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type presetRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPresetRepository(db *pgxpool.Pool) PresetRepository {
+	return &presetRepo{db: db}
+}
+
+const presetColumns = "id, name, description, params, created_at, updated_at"
+
+func scanPreset(row pgx.Row) (domain.FilterPreset, error) {
+	var p domain.FilterPreset
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &p.Params, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, ErrNotFound
+	}
+	return p, err
+}
+
+func (r *presetRepo) List(ctx context.Context) ([]domain.FilterPreset, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+presetColumns+" FROM filter_presets WHERE user_id = $1 ORDER BY name",
+		domain.UserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error al listar presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := []domain.FilterPreset{}
+	for rows.Next() {
+		p, err := scanPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, p)
+	}
+	return presets, rows.Err()
+}
+
+func (r *presetRepo) GetByID(ctx context.Context, id int) (domain.FilterPreset, error) {
+	return scanPreset(r.db.QueryRow(ctx, "SELECT "+presetColumns+" FROM filter_presets WHERE id = $1 AND user_id = $2",
+		id, domain.UserIDFromContext(ctx)))
+}
+
+func (r *presetRepo) GetByName(ctx context.Context, name string) (domain.FilterPreset, error) {
+	return scanPreset(r.db.QueryRow(ctx, "SELECT "+presetColumns+" FROM filter_presets WHERE name = $1 AND user_id = $2",
+		name, domain.UserIDFromContext(ctx)))
+}
+
+func (r *presetRepo) Create(ctx context.Context, in domain.FilterPresetInput) (domain.FilterPreset, error) {
+	p, err := scanPreset(r.db.QueryRow(ctx, `
+		INSERT INTO filter_presets (user_id, name, description, params)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+presetColumns, domain.UserIDFromContext(ctx), in.Name, in.Description, in.Params))
+	return p, uniqueViolation(err)
+}
+
+func (r *presetRepo) Update(ctx context.Context, id int, in domain.FilterPresetInput) (domain.FilterPreset, error) {
+	p, err := scanPreset(r.db.QueryRow(ctx, `
+		UPDATE filter_presets
+		SET name = $2, description = $3, params = $4, updated_at = now()
+		WHERE id = $1 AND user_id = $5
+		RETURNING `+presetColumns, id, in.Name, in.Description, in.Params, domain.UserIDFromContext(ctx)))
+	return p, uniqueViolation(err)
+}
+
+func (r *presetRepo) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM filter_presets WHERE id = $1 AND user_id = $2", id, domain.UserIDFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error al eliminar preset: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// uniqueViolation traduce el código 23505 de Postgres a ErrDuplicate
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
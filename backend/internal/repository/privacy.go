@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// GetOfflineStats obtiene cuánto se escuchó sin conexión y el retraso hasta sincronizar
+func (r *spotifyRepo) GetOfflineStats(ctx context.Context, f domain.SpotifyFilters) (domain.OfflineStatsDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		WITH base AS (
+			SELECT
+				ms_played,
+				offline IS TRUE AS is_offline,
+				CASE WHEN offline IS TRUE AND offline_timestamp IS NOT NULL
+					THEN EXTRACT(EPOCH FROM (ts - offline_timestamp)) / 3600.0
+				END AS lag_hours
+			FROM spotify_history
+			%s
+		)
+		SELECT
+			COUNT(*) AS plays,
+			COUNT(*) FILTER (WHERE is_offline) AS offline_plays,
+			COALESCE(ROUND(SUM(ms_played) FILTER (WHERE is_offline) / 60000.0, 2), 0) AS offline_minutes,
+			COALESCE(ROUND(COUNT(*) FILTER (WHERE is_offline)::numeric / NULLIF(COUNT(*), 0), 4), 0) AS offline_share,
+			COALESCE(ROUND(AVG(lag_hours)::numeric, 2), 0) AS avg_sync_lag_hours,
+			COALESCE(ROUND((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY lag_hours))::numeric, 2), 0) AS median_sync_lag_hours,
+			COALESCE(ROUND(MAX(lag_hours)::numeric, 2), 0) AS max_sync_lag_hours
+		FROM base`, where)
+
+	var d domain.OfflineStatsDTO
+	err := r.db.QueryRow(ctx, query, args...).Scan(
+		&d.Plays, &d.OfflinePlays, &d.OfflineMinutes, &d.OfflineShare,
+		&d.AvgSyncLagHours, &d.MedianSyncLagHours, &d.MaxSyncLagHours,
+	)
+	return d, err
+}
+
+// GetIncognitoStats obtiene cuánto se escuchó en sesiones privadas (sin top de artistas)
+func (r *spotifyRepo) GetIncognitoStats(ctx context.Context, f domain.SpotifyFilters) (domain.IncognitoStatsDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*) AS plays,
+			COUNT(*) FILTER (WHERE incognito_mode) AS incognito_plays,
+			COALESCE(ROUND(SUM(ms_played) FILTER (WHERE incognito_mode) / 60000.0, 2), 0) AS incognito_minutes,
+			COALESCE(ROUND(COUNT(*) FILTER (WHERE incognito_mode)::numeric / NULLIF(COUNT(*), 0), 4), 0) AS incognito_share
+		FROM spotify_history
+		%s`, where)
+
+	var d domain.IncognitoStatsDTO
+	err := r.db.QueryRow(ctx, query, args...).Scan(&d.Plays, &d.IncognitoPlays, &d.IncognitoMinutes, &d.IncognitoShare)
+	return d, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Expresión agregada de cada criterio de sort_by. RANK() y ORDER BY usan la misma,
+// así la posición informada siempre coincide con el orden de la lista
+var rankingSortExprs = map[domain.RankingSort]string{
+	domain.SortByPlays:        "COUNT(*)",
+	domain.SortByMinutes:      "SUM(ms_played)",
+	domain.SortByDistinctDays: "COUNT(DISTINCT ts::date)",
+	domain.SortByFirstPlayed:  "MIN(ts)",
+	domain.SortByLastPlayed:   "MAX(ts)",
+}
+
+// rankingMetricColumns son las columnas de domain.RankingMetrics, en el orden de rankingMetricDest
+const rankingMetricColumns = `COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played,
+			COUNT(*) AS times_played,
+			COUNT(DISTINCT ts::date) AS distinct_days,
+			MIN(ts) AS first_played,
+			MAX(ts) AS last_played`
+
+func rankingMetricDest(m *domain.RankingMetrics) []interface{} {
+	return []interface{}{&m.MinutesPlayed, &m.TimesPlayed, &m.DistinctDays, &m.FirstPlayed, &m.LastPlayed}
+}
+
+// rankingOrder retorna la expresión de orden para RANK() y ORDER BY (ej. "COUNT(*) DESC")
+func rankingOrder(f domain.SpotifyFilters) string {
+	expr, ok := rankingSortExprs[f.SortBy]
+	if !ok {
+		expr = rankingSortExprs[domain.SortByPlays]
+	}
+	if f.Order == domain.SortAsc {
+		return expr + " ASC"
+	}
+	return expr + " DESC"
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Columnas de cada grupo de búsqueda: texto comparado y artista informado junto al resultado
+var searchColumns = map[domain.SearchKind]struct{ name, artist string }{
+	domain.SearchArtists: {"artist_name", "''"},
+	domain.SearchAlbums:  {"album_name", "artist_name"},
+	domain.SearchTracks:  {"track_name", "artist_name"},
+}
+
+// SearchEntities busca por similitud de trigramas sin distinguir mayúsculas ni acentos
+// ("cafe tacuba" encuentra "Café Tacvba"). % compara el texto completo y <% permite
+// que el término calce con una parte del nombre, útil para autocompletar
+func (r *spotifyRepo) SearchEntities(ctx context.Context, f domain.SpotifyFilters, kind domain.SearchKind, term string, limit int) ([]domain.SearchHitDTO, error) {
+	cols, ok := searchColumns[kind]
+	if !ok {
+		return nil, fmt.Errorf("grupo de búsqueda desconocido: %s", kind)
+	}
+	where, args := buildWhereClause(ctx, f)
+	termArg := len(args) + 1
+	norm := fmt.Sprintf("f_unaccent(lower(%s))", cols.name)
+	termExpr := fmt.Sprintf("f_unaccent(lower($%d))", termArg)
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s AS name,
+			%s AS artist,
+			COUNT(*) AS plays,
+			COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) AS minutes_played,
+			GREATEST(similarity(%s, %s), word_similarity(%s, %s)) AS score
+		FROM spotify_history
+		%s AND %s IS NOT NULL AND (%s %% %s OR %s <%% %s)
+		GROUP BY 1, 2
+		ORDER BY score DESC, plays DESC
+		LIMIT $%d`,
+		cols.name, cols.artist,
+		norm, termExpr, termExpr, norm,
+		where, cols.name, norm, termExpr, termExpr, norm,
+		termArg+1)
+
+	args = append(args, term, limit)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar %s: %w", kind, err)
+	}
+	defer rows.Close()
+
+	hits := []domain.SearchHitDTO{}
+	for rows.Next() {
+		var h domain.SearchHitDTO
+		if err := rows.Scan(&h.Name, &h.ArtistName, &h.Plays, &h.MinutesPlayed, &h.Score); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
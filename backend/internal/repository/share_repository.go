@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ShareRepository guarda los enlaces públicos (tabla share_links). List, GetByID, Create y Revoke
+// operan sobre el usuario del contexto; GetActive es la lectura pública por token
+type ShareRepository interface {
+	List(ctx context.Context) ([]domain.ShareLink, error)
+	GetByID(ctx context.Context, id int) (domain.ShareLink, error)
+	Create(ctx context.Context, tokenHash []byte, payload domain.WrappedDTO, expiresAt *time.Time) (domain.ShareLink, error)
+	Revoke(ctx context.Context, id int) error
+	GetActive(ctx context.Context, tokenHash []byte) (domain.SharedWrappedDTO, error)
+}
+
+type shareRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewShareRepository(db *pgxpool.Pool) ShareRepository {
+	return &shareRepo{db: db}
+}
+
+const shareColumns = "id, payload->'period', created_at, expires_at, revoked_at"
+
+func scanShare(row pgx.Row) (domain.ShareLink, error) {
+	var s domain.ShareLink
+	err := row.Scan(&s.ID, &s.Period, &s.CreatedAt, &s.ExpiresAt, &s.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, ErrNotFound
+	}
+	return s, err
+}
+
+func (r *shareRepo) List(ctx context.Context) ([]domain.ShareLink, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+shareColumns+" FROM share_links WHERE user_id = $1 ORDER BY created_at DESC",
+		domain.UserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error al listar enlaces compartidos: %w", err)
+	}
+	defer rows.Close()
+
+	links := []domain.ShareLink{}
+	for rows.Next() {
+		s, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, s)
+	}
+	return links, rows.Err()
+}
+
+func (r *shareRepo) GetByID(ctx context.Context, id int) (domain.ShareLink, error) {
+	return scanShare(r.db.QueryRow(ctx, "SELECT "+shareColumns+" FROM share_links WHERE id = $1 AND user_id = $2",
+		id, domain.UserIDFromContext(ctx)))
+}
+
+func (r *shareRepo) Create(ctx context.Context, tokenHash []byte, payload domain.WrappedDTO, expiresAt *time.Time) (domain.ShareLink, error) {
+	return scanShare(r.db.QueryRow(ctx, `
+		INSERT INTO share_links (user_id, token_hash, payload, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+shareColumns, domain.UserIDFromContext(ctx), tokenHash, payload, expiresAt))
+}
+
+// Revoke conserva la fila (el listado muestra revoked_at); revocar dos veces es ErrNotFound
+func (r *shareRepo) Revoke(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE share_links SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, domain.UserIDFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error al revocar enlace: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetActive retorna ErrNotFound tanto para tokens inexistentes como revocados o expirados
+func (r *shareRepo) GetActive(ctx context.Context, tokenHash []byte) (domain.SharedWrappedDTO, error) {
+	var s domain.SharedWrappedDTO
+	err := r.db.QueryRow(ctx, `
+		SELECT payload, created_at, expires_at FROM share_links
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())`, tokenHash).
+		Scan(&s.Wrapped, &s.CreatedAt, &s.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, ErrNotFound
+	}
+	return s, err
+}
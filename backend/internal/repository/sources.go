@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+// Columnas comunes del desglose, en el orden de domain.SourceBreakdownDTO
+const sourceBreakdownColumns = `
+	COUNT(*) AS plays,
+	COUNT(*) FILTER (WHERE shuffle) AS shuffle_plays,
+	COALESCE(ROUND(COUNT(*) FILTER (WHERE shuffle)::numeric / NULLIF(COUNT(*), 0), 4), 0) AS shuffle_share,
+	COALESCE(ROUND(COUNT(*) FILTER (WHERE reason_start IN ('clickrow', 'playbtn'))::numeric / NULLIF(COUNT(*), 0), 4), 0) AS click_share,
+	COALESCE(ROUND(COUNT(*) FILTER (WHERE reason_start = 'autoplay')::numeric / NULLIF(COUNT(*), 0), 4), 0) AS autoplay_share,
+	COALESCE(ROUND(COUNT(*) FILTER (WHERE reason_start = 'trackdone')::numeric / NULLIF(COUNT(*), 0), 4), 0) AS trackdone_share,
+	COALESCE(ROUND(COUNT(*) FILTER (WHERE reason_start NOT IN ('clickrow', 'playbtn', 'autoplay', 'trackdone'))::numeric / NULLIF(COUNT(*), 0), 4), 0) AS other_share,
+	COALESCE(ROUND(COUNT(*) FILTER (WHERE reason_start IS NULL)::numeric / NULLIF(COUNT(*), 0), 4), 0) AS unknown_share`
+
+// GetSourceBreakdown obtiene el desglose global de origen de las reproducciones
+func (r *spotifyRepo) GetSourceBreakdown(ctx context.Context, f domain.SpotifyFilters) (domain.SourceBreakdownDTO, error) {
+	where, args := buildWhereClause(ctx, f)
+	query := fmt.Sprintf(`SELECT %s FROM spotify_history %s`, sourceBreakdownColumns, where)
+
+	var d domain.SourceBreakdownDTO
+	err := r.db.QueryRow(ctx, query, args...).Scan(
+		&d.Plays, &d.ShufflePlays, &d.ShuffleShare, &d.ClickShare,
+		&d.AutoplayShare, &d.TrackDoneShare, &d.OtherShare, &d.UnknownShare,
+	)
+	return d, err
+}
+
+// GetArtistSourceBreakdown obtiene el desglose por artista, ordenado por reproducciones
+func (r *spotifyRepo) GetArtistSourceBreakdown(ctx context.Context, f domain.SpotifyFilters) ([]domain.ArtistSourceBreakdownDTO, int, error) {
+	where, args := buildWhereClause(ctx, f)
+
+	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT artist_name) FROM spotify_history %s", where)
+	total, err := r.countRows(ctx, countQuery, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al contar artistas: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			RANK() OVER (ORDER BY COUNT(*) DESC) AS ranking,
+			artist_name,
+			%s
+		FROM spotify_history
+		%s
+		GROUP BY artist_name
+		ORDER BY plays DESC
+		LIMIT $%d OFFSET $%d`, sourceBreakdownColumns, where, len(args)+1, len(args)+2)
+
+	pagedArgs := append(args, f.Limit, f.Offset())
+	rows, err := r.db.Query(ctx, query, pagedArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var resul []domain.ArtistSourceBreakdownDTO
+	for rows.Next() {
+		var d domain.ArtistSourceBreakdownDTO
+		if err := rows.Scan(
+			&d.Ranking, &d.ArtistName,
+			&d.Plays, &d.ShufflePlays, &d.ShuffleShare, &d.ClickShare,
+			&d.AutoplayShare, &d.TrackDoneShare, &d.OtherShare, &d.UnknownShare,
+		); err != nil {
+			return nil, 0, err
+		}
+		resul = append(resul, d)
+	}
+
+	if resul == nil {
+		resul = []domain.ArtistSourceBreakdownDTO{}
+	}
+
+	return resul, total, rows.Err()
+}
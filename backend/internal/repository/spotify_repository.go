@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,6 +22,31 @@ type SpotifyRepository interface {
 	GetHistoryEvolution(ctx context.Context, f domain.SpotifyFilters) ([]domain.HistoryEvolutionDTO, error)
 	GetRankedSongs(ctx context.Context, f domain.SpotifyFilters, artistTrack domain.ArtistTrackFilters, limit int) ([]domain.SongRankingDTO, error)
 	GetRankedArtist(ctx context.Context, f domain.SpotifyFilters, artist domain.ArtistTrackFilters, limit int) ([]domain.ArtistRankingDTO, error)
+
+	// Variantes en streaming para exportaciones, entregan fila a fila sin acumular
+	StreamTopArtists(ctx context.Context, f domain.SpotifyFilters, fn func(domain.ArtistRankingDTO) error) error
+	StreamTopSongs(ctx context.Context, f domain.SpotifyFilters, fn func(domain.SongRankingDTO) error) error
+	StreamTopAlbums(ctx context.Context, f domain.SpotifyFilters, fn func(domain.AlbumRankingDTO) error) error
+	StreamHabitsByTimeOfDay(ctx context.Context, f domain.SpotifyFilters, fn func(domain.HabitTimeDTO) error) error
+	StreamHabitsByDayOfWeek(ctx context.Context, f domain.SpotifyFilters, fn func(domain.HabitTimeDTO) error) error
+	StreamYearlyStats(ctx context.Context, f domain.SpotifyFilters, fn func(domain.YearlyStatsDTO) error) error
+	StreamHistoryEvolution(ctx context.Context, f domain.SpotifyFilters, fn func(domain.HistoryEvolutionDTO) error) error
+
+	GetDiversityMetrics(ctx context.Context, f domain.SpotifyFilters, dim domain.DiversityDimension) (domain.DiversityMetricsDTO, error)
+	GetMonthlyDiversity(ctx context.Context, f domain.SpotifyFilters, dim domain.DiversityDimension) ([]domain.MonthlyDiversityDTO, error)
+	GetBinges(ctx context.Context, f domain.SpotifyFilters, b domain.BingeFilters) ([]domain.BingeDTO, int, error)
+	GetCountryStats(ctx context.Context, f domain.SpotifyFilters) ([]domain.CountryStatsDTO, error)
+	GetTopArtistsByCountry(ctx context.Context, f domain.SpotifyFilters, limit int) (map[string][]domain.ArtistRankingDTO, error)
+	GetCountryTimeline(ctx context.Context, f domain.SpotifyFilters) ([]domain.CountryTripDTO, error)
+	GetPlatformStats(ctx context.Context, f domain.SpotifyFilters) ([]domain.PlatformStatsDTO, error)
+	GetPlatformEvolution(ctx context.Context, f domain.SpotifyFilters) ([]domain.PlatformEvolutionDTO, error)
+	GetSourceBreakdown(ctx context.Context, f domain.SpotifyFilters) (domain.SourceBreakdownDTO, error)
+	GetArtistSourceBreakdown(ctx context.Context, f domain.SpotifyFilters) ([]domain.ArtistSourceBreakdownDTO, int, error)
+	GetOfflineStats(ctx context.Context, f domain.SpotifyFilters) (domain.OfflineStatsDTO, error)
+	GetIncognitoStats(ctx context.Context, f domain.SpotifyFilters) (domain.IncognitoStatsDTO, error)
+	GetPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage) ([]domain.SpotifyRecord, error)
+	StreamPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage, fn func(domain.SpotifyRecord) error) error
+	SearchEntities(ctx context.Context, f domain.SpotifyFilters, kind domain.SearchKind, term string, limit int) ([]domain.SearchHitDTO, error)
 }
 
 type spotifyRepo struct {
@@ -32,11 +58,12 @@ func NewSpotifyRepository(db *pgxpool.Pool) SpotifyRepository {
 }
 
 // Función auxiliar para construir WHERE dinámico
-// Solo parametro search es obligatorio, pero puede ser "" para no filtrar por busqueda
-func buildWhereClause(f domain.SpotifyFilters) (string, []interface{}) {
-	clauses := []string{"spotify_uri LIKE 'spotify:track:%'", "ms_played > 10000"}
-	args := []interface{}{}
-	placeholder := 1
+// Solo parametro search es obligatorio, pero puede ser "" para no filtrar por busqueda.
+// Toda consulta queda acotada al usuario del contexto ($1)
+func buildWhereClause(ctx context.Context, f domain.SpotifyFilters) (string, []interface{}) {
+	clauses := []string{"user_id = $1", "spotify_uri LIKE 'spotify:track:%'", "ms_played > 10000"}
+	args := []interface{}{domain.UserIDFromContext(ctx)}
+	placeholder := 2
 
 	if f.StartDate != nil {
 		clauses = append(clauses, fmt.Sprintf("ts >= $%d", placeholder))
@@ -53,25 +80,104 @@ func buildWhereClause(f domain.SpotifyFilters) (string, []interface{}) {
 		args = append(args, "%"+f.Search+"%")
 		placeholder++
 	}
-	if f.Artist != "" {
-		clauses = append(clauses, fmt.Sprintf("artist_name ILIKE $%d", placeholder))
-		args = append(args, "%"+f.Artist+"%")
+	// Filtros multivalor: cada uno es un solo parámetro de tipo arreglo (= ANY / <> ALL)
+	addList := func(expr string, values []string, exclude bool) {
+		if len(values) == 0 {
+			return
+		}
+		if exclude {
+			clauses = append(clauses, fmt.Sprintf("%s <> ALL($%d)", expr, placeholder))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("%s = ANY($%d)", expr, placeholder))
+		}
+		args = append(args, values)
 		placeholder++
 	}
-	if f.Track != "" {
-		clauses = append(clauses, fmt.Sprintf("track_name ILIKE $%d", placeholder))
-		args = append(args, "%"+f.Track+"%")
+	// Nombres de artista o canción, según el modo exact (lower = lower) o contains (ILIKE '%x%')
+	addNames := func(column string, values []string, exclude bool) {
+		if len(values) == 0 {
+			return
+		}
+		if f.Match == domain.MatchExact {
+			addList(fmt.Sprintf("lower(COALESCE(%s, ''))", column), mapStrings(values, strings.ToLower), exclude)
+			return
+		}
+		patterns := mapStrings(values, func(v string) string { return "%" + v + "%" })
+		if exclude {
+			clauses = append(clauses, fmt.Sprintf("NOT (COALESCE(%s, '') ILIKE ANY($%d))", column, placeholder))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("%s ILIKE ANY($%d)", column, placeholder))
+		}
+		args = append(args, patterns)
 		placeholder++
 	}
-	if f.StartHour != nil && f.EndHour != nil {
+
+	addNames("artist_name", f.Artists, false)
+	addNames("track_name", f.Tracks, false)
+	addNames("artist_name", f.ExcludeArtists, true)
+	addNames("track_name", f.ExcludeTracks, true)
+	addList(platformFamilyExpr, familyStrings(f.Platforms), false)
+	addList(platformFamilyExpr, familyStrings(f.ExcludePlatforms), true)
+	addList(countryExpr, f.Countries, false)
+	addList(countryExpr, f.ExcludeCountries, true)
+	if !f.IncludeExcluded {
+		clauses = append(clauses, exclusionClause)
+	}
+	if f.ExcludeIncognito {
+		clauses = append(clauses, "incognito_mode IS NOT TRUE")
+	}
+	if f.IncognitoOnly {
+		clauses = append(clauses, "incognito_mode IS TRUE")
+	}
+	// Rango horario: con ambos extremos y StartHour > EndHour (22 a 3) el rango cruza la medianoche
+	switch {
+	case f.StartHour != nil && f.EndHour != nil && *f.StartHour > *f.EndHour:
+		clauses = append(clauses, fmt.Sprintf("(EXTRACT(HOUR FROM ts) >= $%d OR EXTRACT(HOUR FROM ts) <= $%d)", placeholder, placeholder+1))
+		args = append(args, *f.StartHour, *f.EndHour)
+		placeholder += 2
+	case f.StartHour != nil && f.EndHour != nil:
 		clauses = append(clauses, fmt.Sprintf("EXTRACT(HOUR FROM ts) BETWEEN $%d AND $%d", placeholder, placeholder+1))
 		args = append(args, *f.StartHour, *f.EndHour)
 		placeholder += 2
+	case f.StartHour != nil:
+		clauses = append(clauses, fmt.Sprintf("EXTRACT(HOUR FROM ts) >= $%d", placeholder))
+		args = append(args, *f.StartHour)
+		placeholder++
+	case f.EndHour != nil:
+		clauses = append(clauses, fmt.Sprintf("EXTRACT(HOUR FROM ts) <= $%d", placeholder))
+		args = append(args, *f.EndHour)
+		placeholder++
+	}
+	// nil = sin filtro; un slice vacío (intersección vacía en split=day_type) no calza con nada
+	if f.Weekdays != nil {
+		days := make([]int, len(f.Weekdays))
+		for i, d := range f.Weekdays {
+			days[i] = int(d)
+		}
+		clauses = append(clauses, fmt.Sprintf("EXTRACT(DOW FROM ts)::int = ANY($%d)", placeholder))
+		args = append(args, days)
+		placeholder++
 	}
 
 	return "WHERE " + strings.Join(clauses, " AND "), args
 }
 
+func mapStrings(values []string, fn func(string) string) []string {
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = fn(v)
+	}
+	return res
+}
+
+func familyStrings(families []domain.DeviceFamily) []string {
+	res := make([]string, len(families))
+	for i, f := range families {
+		res[i] = string(f)
+	}
+	return res
+}
+
 // Filtros de artista y track,  afectan la VISUALIZACIÓN (Qué artista o canción quiero ver)
 func buildWhereArtistTrackClause(f domain.ArtistTrackFilters, startPlaceholder int) (string, []interface{}) {
 	var clauses []string
@@ -98,7 +204,7 @@ func buildWhereArtistTrackClause(f domain.ArtistTrackFilters, startPlaceholder i
 
 // GetTotalStats obtiene horas totales y diversidad musical
 func (r *spotifyRepo) GetTotalStats(ctx context.Context, f domain.SpotifyFilters) (domain.TotalStatsDTO, error) {
-	where, args := buildWhereClause(f)
+	where, args := buildWhereClause(ctx, f)
 	query := fmt.Sprintf(`
 		SELECT 
 			COALESCE(ROUND(SUM(ms_played) / 3600000.0, 2), 0) as total_hours,
@@ -119,97 +225,143 @@ func (r *spotifyRepo) GetTotalStats(ctx context.Context, f domain.SpotifyFilters
 	return stats, err
 }
 
+// queryEach ejecuta la consulta y entrega cada fila a fn sin acumularlas en memoria.
+// Es la base tanto de los listados JSON como de las exportaciones (CSV, NDJSON, XLSX)
+func queryEach[T any](ctx context.Context, db *pgxpool.Pool, query string, args []interface{}, scan func(pgx.Rows) (T, error), fn func(T) error) error {
+	var q interface {
+		Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	} = db
+	// SET LOCAL solo dura hasta el fin de la transacción: la conexión vuelve al pool con el timeout normal
+	if domain.QueryTimeoutDisabled(ctx) {
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+		if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+			return err
+		}
+		q = tx
+	}
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// pageArgs retorna LIMIT y OFFSET. Un LIMIT NULL en Postgres equivale a sin límite (exportación completa)
+func pageArgs(f domain.SpotifyFilters) (interface{}, int) {
+	if f.Unpaged {
+		return nil, 0
+	}
+	return f.Limit, f.Offset()
+}
+
 // GetTopArtists obtiene el ranking de artistas
 func (r *spotifyRepo) GetTopArtists(ctx context.Context, f domain.SpotifyFilters) ([]domain.ArtistRankingDTO, int, error) {
-	where, args := buildWhereClause(f)
+	where, args := buildWhereClause(ctx, f)
 
 	// Query para el total (sin LIMIT ni OFFSET)
 	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT artist_name) FROM spotify_history %s", where)
 	total, _ := r.countRows(ctx, countQuery, args)
 
+	rankings := []domain.ArtistRankingDTO{}
+	err := r.StreamTopArtists(ctx, f, func(dto domain.ArtistRankingDTO) error {
+		rankings = append(rankings, dto)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rankings, total, nil
+}
+
+func (r *spotifyRepo) StreamTopArtists(ctx context.Context, f domain.SpotifyFilters, fn func(domain.ArtistRankingDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
+
 	// Query con paginación
+	order := rankingOrder(f)
 	query := fmt.Sprintf(`
 		SELECT 
-			RANK() OVER (ORDER BY COUNT(*) DESC) AS ranking,
+			RANK() OVER (ORDER BY %s) AS ranking,
 			artist_name, 
-			COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) as minutes_played,
-			COUNT(*) as times_played
+			%s
 		FROM spotify_history 
 		%s
 		GROUP BY artist_name
-		ORDER BY 3 DESC
-		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+		ORDER BY %s, artist_name
+		LIMIT $%d OFFSET $%d`, order, rankingMetricColumns, where, order, len(args)+1, len(args)+2)
 
-	args = append(args, f.Limit, f.Offset())
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var rankings []domain.ArtistRankingDTO
-	for rows.Next() {
+	limit, offset := pageArgs(f)
+	args = append(args, limit, offset)
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.ArtistRankingDTO, error) {
 		var dto domain.ArtistRankingDTO
-		if err := rows.Scan(&dto.Ranking, &dto.ArtistName, &dto.MinutesPlayed, &dto.TimesPlayed); err != nil {
-			return nil, 0, err
-		}
-		rankings = append(rankings, dto)
-	}
-
-	if rankings == nil {
-		rankings = []domain.ArtistRankingDTO{}
-	}
-
-	return rankings, total, nil
+		err := rows.Scan(append([]interface{}{&dto.Ranking, &dto.ArtistName}, rankingMetricDest(&dto.RankingMetrics)...)...)
+		return dto, err
+	}, fn)
 }
 
-// GetTopAlbums obtiene el ranking de álbumes
+// GetTopSongs obtiene el ranking de canciones
 // Util para wrappeds segun anio, mes, y estaciones del anio (capa service) LIMIT 100
 func (r *spotifyRepo) GetTopSongs(ctx context.Context, f domain.SpotifyFilters) ([]domain.SongRankingDTO, int, error) {
-	where, args := buildWhereClause(f)
+	where, args := buildWhereClause(ctx, f)
 
 	// Obtener el total de registros únicos para la paginación
 	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT (track_name, artist_name)) FROM spotify_history %s", where)
 	total, err := r.countRows(ctx, countQuery, args)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error al contar canciones: %v", err)
+		return nil, 0, fmt.Errorf("error al contar canciones: %w", err)
 	}
 
+	// Si no hay resultados, retornamos un slice vacío (no nil)
+	rankings := []domain.SongRankingDTO{}
+	err = r.StreamTopSongs(ctx, f, func(dto domain.SongRankingDTO) error {
+		rankings = append(rankings, dto)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rankings, total, nil
+}
+
+func (r *spotifyRepo) StreamTopSongs(ctx context.Context, f domain.SpotifyFilters, fn func(domain.SongRankingDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
+
 	// Query principal con RANK, LIMIT y OFFSET
+	order := rankingOrder(f)
 	query := fmt.Sprintf(`
 		SELECT 
-			RANK() OVER (ORDER BY COUNT(*) DESC) AS ranking, 
+			RANK() OVER (ORDER BY %s) AS ranking, 
 			track_name, 
 			artist_name, 
-			COUNT(*) AS times_played
+			%s
 		FROM spotify_history
 		%s
 		GROUP BY track_name, artist_name
-		ORDER BY times_played DESC
-		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
-
-	pagedArgs := append(args, f.Limit, f.Offset())
-	rows, err := r.db.Query(ctx, query, pagedArgs...)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
+		ORDER BY %s, track_name, artist_name
+		LIMIT $%d OFFSET $%d`, order, rankingMetricColumns, where, order, len(args)+1, len(args)+2)
 
-	var rankings []domain.SongRankingDTO
-	for rows.Next() {
+	limit, offset := pageArgs(f)
+	args = append(args, limit, offset)
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.SongRankingDTO, error) {
 		var dto domain.SongRankingDTO
-		if err := rows.Scan(&dto.Ranking, &dto.TrackName, &dto.ArtistName, &dto.TimesPlayed); err != nil {
-			return nil, 0, err
-		}
-		rankings = append(rankings, dto)
-	}
-
-	// Si no hay resultados, retornamos un slice vacío (no nil)
-	if rankings == nil {
-		rankings = []domain.SongRankingDTO{}
-	}
-
-	return rankings, total, nil
+		err := rows.Scan(append([]interface{}{&dto.Ranking, &dto.TrackName, &dto.ArtistName}, rankingMetricDest(&dto.RankingMetrics)...)...)
+		return dto, err
+	}, fn)
 }
 
 func (r *spotifyRepo) countRows(ctx context.Context, tableQuery string, args []interface{}) (int, error) {
@@ -220,53 +372,63 @@ func (r *spotifyRepo) countRows(ctx context.Context, tableQuery string, args []i
 
 // GetTopAlbums obtiene el ranking de álbumes
 func (r *spotifyRepo) GetTopAlbums(ctx context.Context, f domain.SpotifyFilters) ([]domain.AlbumRankingDTO, int, error) {
-	where, args := buildWhereClause(f)
+	where, args := buildWhereClause(ctx, f)
 
 	// Obtener el total de registros únicos
 	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT (album_name, artist_name)) FROM spotify_history %s", where)
 	total, err := r.countRows(ctx, countQuery, args)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error al contar álbumes: %v", err)
+		return nil, 0, fmt.Errorf("error al contar álbumes: %w", err)
 	}
 
+	rankings := []domain.AlbumRankingDTO{}
+	err = r.StreamTopAlbums(ctx, f, func(dto domain.AlbumRankingDTO) error {
+		rankings = append(rankings, dto)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rankings, total, nil
+}
+
+func (r *spotifyRepo) StreamTopAlbums(ctx context.Context, f domain.SpotifyFilters, fn func(domain.AlbumRankingDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
+
+	order := rankingOrder(f)
 	query := fmt.Sprintf(`
 		SELECT 
-			RANK() OVER (ORDER BY COUNT(*) DESC) AS ranking, 
+			RANK() OVER (ORDER BY %s) AS ranking, 
 			album_name, 
 			artist_name, 
-			COUNT(*) AS times_played
+			%s
 		FROM spotify_history
 		%s
 		GROUP BY album_name, artist_name
-		ORDER BY times_played DESC
-		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
-
-	pagedArgs := append(args, f.Limit, f.Offset())
-	rows, err := r.db.Query(ctx, query, pagedArgs...)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
+		ORDER BY %s, album_name, artist_name
+		LIMIT $%d OFFSET $%d`, order, rankingMetricColumns, where, order, len(args)+1, len(args)+2)
 
-	var rankings []domain.AlbumRankingDTO
-	for rows.Next() {
+	limit, offset := pageArgs(f)
+	args = append(args, limit, offset)
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.AlbumRankingDTO, error) {
 		var dto domain.AlbumRankingDTO
-		if err := rows.Scan(&dto.Ranking, &dto.AlbumName, &dto.ArtistName, &dto.TimesPlayed); err != nil {
-			return nil, 0, err
-		}
-		rankings = append(rankings, dto)
-	}
-
-	if rankings == nil {
-		rankings = []domain.AlbumRankingDTO{}
-	}
-
-	return rankings, total, nil
+		err := rows.Scan(append([]interface{}{&dto.Ranking, &dto.AlbumName, &dto.ArtistName}, rankingMetricDest(&dto.RankingMetrics)...)...)
+		return dto, err
+	}, fn)
 }
 
 // Momentos del dia por bloque horario, cantidad de escuchas
 func (r *spotifyRepo) GetHabitsByTimeOfDay(ctx context.Context, f domain.SpotifyFilters) ([]domain.HabitTimeDTO, error) {
-	where, args := buildWhereClause(f)
+	var res []domain.HabitTimeDTO
+	err := r.StreamHabitsByTimeOfDay(ctx, f, func(d domain.HabitTimeDTO) error {
+		res = append(res, d)
+		return nil
+	})
+	return res, err
+}
+
+func (r *spotifyRepo) StreamHabitsByTimeOfDay(ctx context.Context, f domain.SpotifyFilters, fn func(domain.HabitTimeDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
 	query := fmt.Sprintf(`
         SELECT 
             CASE 
@@ -280,26 +442,25 @@ func (r *spotifyRepo) GetHabitsByTimeOfDay(ctx context.Context, f domain.Spotify
         GROUP BY label 
 		ORDER BY count DESC`, where)
 
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.HabitTimeDTO
-	for rows.Next() {
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.HabitTimeDTO, error) {
 		var d domain.HabitTimeDTO
-		if err := rows.Scan(&d.Label, &d.Count); err != nil {
-			return nil, err
-		}
-		res = append(res, d)
-	}
-	return res, nil
+		err := rows.Scan(&d.Label, &d.Count)
+		return d, err
+	}, fn)
 }
 
 // Escuchas segun dia de la semana (ingles)
 func (r *spotifyRepo) GetHabitsByDayOfWeek(ctx context.Context, f domain.SpotifyFilters) ([]domain.HabitTimeDTO, error) {
-	where, args := buildWhereClause(f)
+	var res []domain.HabitTimeDTO
+	err := r.StreamHabitsByDayOfWeek(ctx, f, func(d domain.HabitTimeDTO) error {
+		res = append(res, d)
+		return nil
+	})
+	return res, err
+}
+
+func (r *spotifyRepo) StreamHabitsByDayOfWeek(ctx context.Context, f domain.SpotifyFilters, fn func(domain.HabitTimeDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
 	query := fmt.Sprintf(`
         SELECT 
 			EXTRACT(DOW FROM ts) AS num_day, 
@@ -308,28 +469,29 @@ func (r *spotifyRepo) GetHabitsByDayOfWeek(ctx context.Context, f domain.Spotify
         GROUP BY EXTRACT(DOW FROM ts)
         ORDER BY EXTRACT(DOW FROM ts)`, where)
 
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.HabitTimeDTO
-	for rows.Next() {
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.HabitTimeDTO, error) {
 		var d domain.HabitTimeDTO
 		var dayVal int // Variable temporal para el escaneo
 		if err := rows.Scan(&dayVal, &d.Count); err != nil {
-			return nil, err
+			return d, err
 		}
 		d.NumDay = &dayVal // Asignamos la dirección de memoria
-		res = append(res, d)
-	}
-	return res, nil
+		return d, nil
+	}, fn)
 }
 
 // Comparativa anual (Tu año en música)
 func (r *spotifyRepo) GetYearlyStats(ctx context.Context, f domain.SpotifyFilters) ([]domain.YearlyStatsDTO, error) {
-	where, args := buildWhereClause(f)
+	var res []domain.YearlyStatsDTO
+	err := r.StreamYearlyStats(ctx, f, func(d domain.YearlyStatsDTO) error {
+		res = append(res, d)
+		return nil
+	})
+	return res, err
+}
+
+func (r *spotifyRepo) StreamYearlyStats(ctx context.Context, f domain.SpotifyFilters, fn func(domain.YearlyStatsDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
 	query := fmt.Sprintf(`
         SELECT 
             EXTRACT(YEAR FROM ts)::int AS year,
@@ -340,26 +502,25 @@ func (r *spotifyRepo) GetYearlyStats(ctx context.Context, f domain.SpotifyFilter
         %s
         GROUP BY year ORDER BY year`, where)
 
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var res []domain.YearlyStatsDTO
-	for rows.Next() {
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.YearlyStatsDTO, error) {
 		var d domain.YearlyStatsDTO
-		if err := rows.Scan(&d.Year, &d.TotalHours, &d.TotalMinutes, &d.TotalSongs); err != nil {
-			return nil, err
-		}
-		res = append(res, d)
-	}
-	return res, nil
+		err := rows.Scan(&d.Year, &d.TotalHours, &d.TotalMinutes, &d.TotalSongs)
+		return d, err
+	}, fn)
 }
 
 // Evolucion historica mensual (Grafico lineas)
 func (r *spotifyRepo) GetHistoryEvolution(ctx context.Context, f domain.SpotifyFilters) ([]domain.HistoryEvolutionDTO, error) {
-	where, args := buildWhereClause(f)
+	var resul []domain.HistoryEvolutionDTO
+	err := r.StreamHistoryEvolution(ctx, f, func(d domain.HistoryEvolutionDTO) error {
+		resul = append(resul, d)
+		return nil
+	})
+	return resul, err
+}
+
+func (r *spotifyRepo) StreamHistoryEvolution(ctx context.Context, f domain.SpotifyFilters, fn func(domain.HistoryEvolutionDTO) error) error {
+	where, args := buildWhereClause(ctx, f)
 	query := fmt.Sprintf(`
 		SELECT
 			TO_CHAR(ts, 'YYYY') AS year,
@@ -371,26 +532,17 @@ func (r *spotifyRepo) GetHistoryEvolution(ctx context.Context, f domain.SpotifyF
 		%s 
 		GROUP BY year, month, year_month
 		ORDER BY year, month;`, where)
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
 
-	var resul []domain.HistoryEvolutionDTO
-	for rows.Next() {
-		var r domain.HistoryEvolutionDTO
-		if err := rows.Scan(&r.Year, &r.Month, &r.YearMonth, &r.HoursMonthly, &r.MinutesMonthly); err != nil {
-			return nil, err
-		}
-		resul = append(resul, r)
-	}
-	return resul, nil
+	return queryEach(ctx, r.db, query, args, func(rows pgx.Rows) (domain.HistoryEvolutionDTO, error) {
+		var d domain.HistoryEvolutionDTO
+		err := rows.Scan(&d.Year, &d.Month, &d.YearMonth, &d.HoursMonthly, &d.MinutesMonthly)
+		return d, err
+	}, fn)
 }
 
 func (r *spotifyRepo) GetRankedSongs(ctx context.Context, f domain.SpotifyFilters, artistTrack domain.ArtistTrackFilters, limit int) ([]domain.SongRankingDTO, error) {
 	// 1. Filtros base (van dentro del ranking para acotar el tiempo/duración)
-	baseWhere, baseArgs := buildWhereClause(f)
+	baseWhere, baseArgs := buildWhereClause(ctx, f)
 
 	// 2. Filtros de selección (van fuera para filtrar el resultado final)
 	// Estos no cambian el cálculo del ranking, solo qué filas se muestran
@@ -401,18 +553,18 @@ func (r *spotifyRepo) GetRankedSongs(ctx context.Context, f domain.SpotifyFilter
 	query := fmt.Sprintf(`
         WITH ranking_completo AS (
             SELECT
-                RANK() OVER (ORDER BY COUNT(*) DESC) AS ranking,
+                RANK() OVER (ORDER BY %s) AS ranking,
                 track_name,
                 artist_name,
-                COUNT(*) AS times_played
+                %s
             FROM spotify_history
             %s
             GROUP BY track_name, artist_name
         )
         SELECT * FROM ranking_completo
         %s
-        ORDER BY ranking ASC
-		LIMIT $%d`, baseWhere, finalWhere, len(allArgs)+1)
+        ORDER BY ranking ASC, track_name, artist_name
+		LIMIT $%d`, rankingOrder(f), rankingMetricColumns, baseWhere, finalWhere, len(allArgs)+1)
 
 	allArgs = append(allArgs, limit)
 	rows, err := r.db.Query(ctx, query, allArgs...)
@@ -424,7 +576,7 @@ func (r *spotifyRepo) GetRankedSongs(ctx context.Context, f domain.SpotifyFilter
 	var resul []domain.SongRankingDTO
 	for rows.Next() {
 		var r domain.SongRankingDTO
-		if err := rows.Scan(&r.Ranking, &r.TrackName, &r.ArtistName, &r.TimesPlayed); err != nil {
+		if err := rows.Scan(append([]interface{}{&r.Ranking, &r.TrackName, &r.ArtistName}, rankingMetricDest(&r.RankingMetrics)...)...); err != nil {
 			return nil, err
 		}
 		resul = append(resul, r)
@@ -433,25 +585,24 @@ func (r *spotifyRepo) GetRankedSongs(ctx context.Context, f domain.SpotifyFilter
 }
 
 func (r *spotifyRepo) GetRankedArtist(ctx context.Context, f domain.SpotifyFilters, artist domain.ArtistTrackFilters, limit int) ([]domain.ArtistRankingDTO, error) {
-	baseWhere, baseArgs := buildWhereClause(f)
+	baseWhere, baseArgs := buildWhereClause(ctx, f)
 	finalWhere, finalArgs := buildWhereArtistTrackClause(artist, len(baseArgs)+1)
 	allArgs := append(baseArgs, finalArgs...)
 
 	query := fmt.Sprintf(`
         WITH ranking_completo AS (
             SELECT
-                RANK() OVER (ORDER BY COUNT(*) DESC) AS ranking,
+                RANK() OVER (ORDER BY %s) AS ranking,
                 artist_name,
-				COALESCE(ROUND(SUM(ms_played) / 60000.0, 2), 0) as minutes_played,
-                COUNT(*) AS times_played
+                %s
             FROM spotify_history
             %s
             GROUP BY artist_name
         )
         SELECT * FROM ranking_completo
         %s
-        ORDER BY ranking ASC
-		LIMIT $%d`, baseWhere, finalWhere, len(allArgs)+1)
+        ORDER BY ranking ASC, artist_name
+		LIMIT $%d`, rankingOrder(f), rankingMetricColumns, baseWhere, finalWhere, len(allArgs)+1)
 
 	allArgs = append(allArgs, limit)
 	rows, err := r.db.Query(ctx, query, allArgs...)
@@ -463,7 +614,7 @@ func (r *spotifyRepo) GetRankedArtist(ctx context.Context, f domain.SpotifyFilte
 	var resul []domain.ArtistRankingDTO
 	for rows.Next() {
 		var r domain.ArtistRankingDTO
-		if err := rows.Scan(&r.Ranking, &r.ArtistName, &r.MinutesPlayed, &r.TimesPlayed); err != nil {
+		if err := rows.Scan(append([]interface{}{&r.Ranking, &r.ArtistName}, rankingMetricDest(&r.RankingMetrics)...)...); err != nil {
 			return nil, err
 		}
 		resul = append(resul, r)
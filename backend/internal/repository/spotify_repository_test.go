@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+)
+
+func hour(h int) *int { return &h }
+
+// Cláusulas que buildWhereClause agrega siempre, antes de los filtros
+const baseWhere = "WHERE user_id = $1 AND spotify_uri LIKE 'spotify:track:%' AND ms_played > 10000"
+
+func TestBuildWhereClauseHoursAndWeekdays(t *testing.T) {
+	tests := []struct {
+		name   string
+		f      domain.SpotifyFilters
+		clause string
+		args   []interface{}
+	}{
+		{
+			name: "sin filtros",
+		},
+		{
+			name:   "rango dentro del día",
+			f:      domain.SpotifyFilters{StartHour: hour(9), EndHour: hour(17)},
+			clause: "EXTRACT(HOUR FROM ts) BETWEEN $2 AND $3",
+			args:   []interface{}{9, 17},
+		},
+		{
+			name:   "rango que cruza la medianoche",
+			f:      domain.SpotifyFilters{StartHour: hour(22), EndHour: hour(3)},
+			clause: "(EXTRACT(HOUR FROM ts) >= $2 OR EXTRACT(HOUR FROM ts) <= $3)",
+			args:   []interface{}{22, 3},
+		},
+		{
+			name:   "una sola hora",
+			f:      domain.SpotifyFilters{StartHour: hour(8), EndHour: hour(8)},
+			clause: "EXTRACT(HOUR FROM ts) BETWEEN $2 AND $3",
+			args:   []interface{}{8, 8},
+		},
+		{
+			name:   "solo desde",
+			f:      domain.SpotifyFilters{StartHour: hour(20)},
+			clause: "EXTRACT(HOUR FROM ts) >= $2",
+			args:   []interface{}{20},
+		},
+		{
+			name:   "solo hasta",
+			f:      domain.SpotifyFilters{EndHour: hour(6)},
+			clause: "EXTRACT(HOUR FROM ts) <= $2",
+			args:   []interface{}{6},
+		},
+		{
+			name:   "días de la semana",
+			f:      domain.SpotifyFilters{Weekdays: []time.Weekday{time.Saturday, time.Sunday}},
+			clause: "EXTRACT(DOW FROM ts)::int = ANY($2)",
+			args:   []interface{}{[]int{6, 0}},
+		},
+		{
+			name:   "ningún día (intersección vacía) no calza con nada",
+			f:      domain.SpotifyFilters{Weekdays: []time.Weekday{}},
+			clause: "EXTRACT(DOW FROM ts)::int = ANY($2)",
+			args:   []interface{}{[]int{}},
+		},
+		{
+			name:   "horas y días numeran los placeholders en orden",
+			f:      domain.SpotifyFilters{StartHour: hour(23), EndHour: hour(1), Weekdays: []time.Weekday{time.Friday}},
+			clause: "(EXTRACT(HOUR FROM ts) >= $2 OR EXTRACT(HOUR FROM ts) <= $3) AND EXTRACT(DOW FROM ts)::int = ANY($4)",
+			args:   []interface{}{23, 1, []int{5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.f.IncludeExcluded = true // Sin la subconsulta de exclusiones, que no depende de estos filtros
+			ctx := domain.WithIdentity(context.Background(), domain.Identity{UserID: 7})
+			where, args := buildWhereClause(ctx, tt.f)
+
+			want := baseWhere
+			if tt.clause != "" {
+				want += " AND " + tt.clause
+			}
+			if where != want {
+				t.Errorf("cláusula:\n  %s\nse esperaba:\n  %s", where, want)
+			}
+			wantArgs := append([]interface{}{7}, tt.args...)
+			if !reflect.DeepEqual(args, wantArgs) {
+				t.Errorf("argumentos %#v, se esperaba %#v", args, wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildWhereClauseSplitByDayType(t *testing.T) {
+	ctx := context.Background()
+	f := domain.SpotifyFilters{Weekdays: []time.Weekday{time.Monday}, IncludeExcluded: true}
+
+	// El filtro weekday=mon deja el fin de semana vacío: debe seguir filtrando, no quedar sin filtro
+	_, args := buildWhereClause(ctx, f.WithWeekdays(domain.WeekendDays))
+	if last := args[len(args)-1]; !reflect.DeepEqual(last, []int{}) {
+		t.Errorf("fin de semana con weekday=mon: último argumento %#v, se esperaba []int{}", last)
+	}
+	where, _ := buildWhereClause(ctx, f.WithWeekdays(domain.WorkingDays))
+	if !strings.HasSuffix(where, "EXTRACT(DOW FROM ts)::int = ANY($2)") {
+		t.Errorf("días hábiles con weekday=mon: %s", where)
+	}
+}
+
+func TestBuildWhereClauseScopesToUser(t *testing.T) {
+	// Sin identidad el usuario es 0, que no existe: falla cerrado en vez de ver datos ajenos
+	_, args := buildWhereClause(context.Background(), domain.SpotifyFilters{IncludeExcluded: true})
+	if args[0] != 0 {
+		t.Errorf("user_id sin identidad = %v, se esperaba 0", args[0])
+	}
+}
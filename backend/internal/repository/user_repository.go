@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserRepository accede a la tabla users
+type UserRepository interface {
+	GetByID(ctx context.Context, id int) (domain.User, error)
+	GetByUsername(ctx context.Context, username string) (domain.User, error)
+	// Ensure retorna el usuario con ese username, creándolo si no existe
+	Ensure(ctx context.Context, username string) (domain.User, error)
+}
+
+type userRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepository(db *pgxpool.Pool) UserRepository {
+	return &userRepo{db: db}
+}
+
+const userColumns = "id, username, display_name, created_at"
+
+func scanUser(row pgx.Row) (domain.User, error) {
+	var u domain.User
+	err := row.Scan(&u.ID, &u.Username, &u.DisplayName, &u.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return u, ErrNotFound
+	}
+	return u, err
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id int) (domain.User, error) {
+	return scanUser(r.db.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id))
+}
+
+func (r *userRepo) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	return scanUser(r.db.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE username = $1", username))
+}
+
+// El DO UPDATE sin cambios permite que RETURNING entregue también el usuario ya existente
+func (r *userRepo) Ensure(ctx context.Context, username string) (domain.User, error) {
+	u, err := scanUser(r.db.QueryRow(ctx, `
+		INSERT INTO users (username) VALUES ($1)
+		ON CONFLICT (username) DO UPDATE SET username = EXCLUDED.username
+		RETURNING `+userColumns, username))
+	if err != nil {
+		return u, fmt.Errorf("error al obtener usuario %q: %w", username, err)
+	}
+	return u, nil
+}
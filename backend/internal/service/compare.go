@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// CompareUsers compara los historiales de dos usuarios con los mismos filtros. Cada lado se consulta
+// con su propia identidad, así también aplica la lista de exclusión de cada uno. Salvo un admin,
+// solo se puede comparar el propio historial con el de otro, y sin ver las métricas del otro
+func (s *spotifyService) CompareUsers(ctx context.Context, usernameA, usernameB string, f domain.SpotifyFilters) (domain.UserComparisonDTO, error) {
+	f.CleanAndValidate()
+	usernameA, usernameB = strings.TrimSpace(usernameA), strings.TrimSpace(usernameB)
+	res := domain.UserComparisonDTO{UserA: usernameA, UserB: usernameB}
+
+	var missing []domain.FieldError
+	for _, p := range []struct{ field, username string }{{"a", usernameA}, {"b", usernameB}} {
+		if p.username == "" {
+			missing = append(missing, domain.FieldError{Field: p.field, Reason: "es obligatorio"})
+		}
+	}
+	if len(missing) > 0 {
+		return res, domain.NewFieldsValidationError(missing)
+	}
+	// Se autoriza antes de buscar a los usuarios: de lo contrario un 404 frente a un 403
+	// revelaría qué usernames existen
+	caller, _ := domain.IdentityFromContext(ctx)
+	if caller.Role != domain.RoleAdmin && caller.Username != usernameA && caller.Username != usernameB {
+		return res, domain.NewForbiddenError("Solo puedes comparar tu propio historial")
+	}
+
+	userA, err := s.lookupUser(ctx, usernameA)
+	if err != nil {
+		return res, err
+	}
+	userB, err := s.lookupUser(ctx, usernameB)
+	if err != nil {
+		return res, err
+	}
+
+	// Los rankings completos ordenados por minutos; f.Limit solo acota las listas de la respuesta
+	all := f
+	all.Unpaged, all.SortBy, all.Order = true, domain.SortByMinutes, domain.SortDesc
+	ctxA := domain.WithIdentity(ctx, domain.Identity{UserID: userA.ID, Username: userA.Username, Role: caller.Role})
+	ctxB := domain.WithIdentity(ctx, domain.Identity{UserID: userB.ID, Username: userB.Username, Role: caller.Role})
+
+	artistsA, err := s.collectArtists(ctxA, all)
+	if err != nil {
+		return res, err
+	}
+	artistsB, err := s.collectArtists(ctxB, all)
+	if err != nil {
+		return res, err
+	}
+	tracksA, err := s.collectSongs(ctxA, all)
+	if err != nil {
+		return res, err
+	}
+	tracksB, err := s.collectSongs(ctxB, all)
+	if err != nil {
+		return res, err
+	}
+
+	res.Compatibility, res.SharedArtists = compareArtists(artistsA, artistsB)
+	res.SharedArtistCount = len(res.SharedArtists)
+	res.OnlyA = onlyIn(artistsA, artistsB, f.Limit)
+	res.OnlyB = onlyIn(artistsB, artistsA, f.Limit)
+
+	res.SharedTracks = compareTracks(tracksA, tracksB)
+	res.SharedTrackCount = len(res.SharedTracks)
+	if len(res.SharedTracks) > 0 {
+		top := res.SharedTracks[0]
+		res.TopSharedTrack = &top
+	}
+
+	res.SharedArtists = res.SharedArtists[:min(len(res.SharedArtists), f.Limit)]
+	res.SharedTracks = res.SharedTracks[:min(len(res.SharedTracks), f.Limit)]
+	if caller.Role != domain.RoleAdmin && usernameA != usernameB {
+		hideOtherUser(&res, caller.Username == usernameA)
+	}
+	return res, nil
+}
+
+// hideOtherUser borra las métricas del usuario que no es el caller: quedan la compatibilidad y los
+// nombres en común, sin los minutos ni reproducciones del otro ni los artistas que solo él escucha
+func hideOtherUser(res *domain.UserComparisonDTO, callerIsA bool) {
+	for i := range res.SharedArtists {
+		a := &res.SharedArtists[i]
+		if callerIsA {
+			a.MinutesB, a.PlaysB, a.ShareB = 0, 0, 0
+		} else {
+			a.MinutesA, a.PlaysA, a.ShareA = 0, 0, 0
+		}
+	}
+	hideTrack := func(t *domain.SharedTrackDTO) {
+		if callerIsA {
+			t.MinutesB, t.PlaysB = 0, 0
+		} else {
+			t.MinutesA, t.PlaysA = 0, 0
+		}
+	}
+	for i := range res.SharedTracks {
+		hideTrack(&res.SharedTracks[i])
+	}
+	if res.TopSharedTrack != nil {
+		hideTrack(res.TopSharedTrack)
+	}
+	if callerIsA {
+		res.OnlyB = nil
+	} else {
+		res.OnlyA = nil
+	}
+}
+
+func (s *spotifyService) lookupUser(ctx context.Context, username string) (domain.User, error) {
+	u, err := s.users.GetByUsername(ctx, username)
+	if errors.Is(err, repository.ErrNotFound) {
+		return u, domain.NewNotFoundError("No existe el usuario %q", username)
+	}
+	return u, err
+}
+
+func (s *spotifyService) collectArtists(ctx context.Context, f domain.SpotifyFilters) ([]domain.ArtistRankingDTO, error) {
+	res := []domain.ArtistRankingDTO{}
+	err := s.repo.StreamTopArtists(ctx, f, func(a domain.ArtistRankingDTO) error {
+		res = append(res, a)
+		return nil
+	})
+	return res, err
+}
+
+func (s *spotifyService) collectSongs(ctx context.Context, f domain.SpotifyFilters) ([]domain.SongRankingDTO, error) {
+	res := []domain.SongRankingDTO{}
+	err := s.repo.StreamTopSongs(ctx, f, func(t domain.SongRankingDTO) error {
+		res = append(res, t)
+		return nil
+	})
+	return res, err
+}
+
+// Los nombres se comparan sin distinguir mayúsculas, igual que la lista de exclusión
+func artistKey(name string) string {
+	return strings.ToLower(name)
+}
+
+func trackKey(track, artist string) string {
+	return strings.ToLower(track) + "\x00" + strings.ToLower(artist)
+}
+
+func totalMinutes(artists []domain.ArtistRankingDTO) float64 {
+	var total float64
+	for _, a := range artists {
+		total += a.MinutesPlayed
+	}
+	return total
+}
+
+// compareArtists retorna la compatibilidad (0-100) y los artistas compartidos, ordenados por la
+// menor de las dos proporciones: primero los que pesan mucho para ambos
+func compareArtists(a, b []domain.ArtistRankingDTO) (float64, []domain.SharedArtistDTO) {
+	totalA, totalB := totalMinutes(a), totalMinutes(b)
+	byName := make(map[string]domain.ArtistRankingDTO, len(b))
+	for _, artist := range b {
+		byName[artistKey(artist.ArtistName)] = artist
+	}
+
+	shared := []domain.SharedArtistDTO{}
+	var overlap float64
+	for _, artistA := range a {
+		artistB, ok := byName[artistKey(artistA.ArtistName)]
+		if !ok || totalA == 0 || totalB == 0 {
+			continue
+		}
+		sa := domain.SharedArtistDTO{
+			ArtistName: artistA.ArtistName,
+			MinutesA:   artistA.MinutesPlayed, MinutesB: artistB.MinutesPlayed,
+			PlaysA: artistA.TimesPlayed, PlaysB: artistB.TimesPlayed,
+			ShareA: artistA.MinutesPlayed / totalA, ShareB: artistB.MinutesPlayed / totalB,
+		}
+		overlap += math.Min(sa.ShareA, sa.ShareB)
+		shared = append(shared, sa)
+	}
+	sort.SliceStable(shared, func(i, j int) bool {
+		return math.Min(shared[i].ShareA, shared[i].ShareB) > math.Min(shared[j].ShareA, shared[j].ShareB)
+	})
+	for i := range shared {
+		shared[i].ShareA = roundTo(shared[i].ShareA, 4)
+		shared[i].ShareB = roundTo(shared[i].ShareB, 4)
+	}
+	return roundTo(overlap*100, 1), shared
+}
+
+// compareTracks ordena las canciones compartidas por la menor cantidad de reproducciones de ambos
+// y desempata por la suma: la primera es la que los dos escucharon más
+func compareTracks(a, b []domain.SongRankingDTO) []domain.SharedTrackDTO {
+	byKey := make(map[string]domain.SongRankingDTO, len(b))
+	for _, t := range b {
+		byKey[trackKey(t.TrackName, t.ArtistName)] = t
+	}
+
+	shared := []domain.SharedTrackDTO{}
+	for _, trackA := range a {
+		trackB, ok := byKey[trackKey(trackA.TrackName, trackA.ArtistName)]
+		if !ok {
+			continue
+		}
+		shared = append(shared, domain.SharedTrackDTO{
+			TrackName: trackA.TrackName, ArtistName: trackA.ArtistName,
+			PlaysA: trackA.TimesPlayed, PlaysB: trackB.TimesPlayed,
+			MinutesA: trackA.MinutesPlayed, MinutesB: trackB.MinutesPlayed,
+		})
+	}
+	sort.SliceStable(shared, func(i, j int) bool {
+		mi, mj := min(shared[i].PlaysA, shared[i].PlaysB), min(shared[j].PlaysA, shared[j].PlaysB)
+		if mi != mj {
+			return mi > mj
+		}
+		return shared[i].PlaysA+shared[i].PlaysB > shared[j].PlaysA+shared[j].PlaysB
+	})
+	return shared
+}
+
+// onlyIn retorna los primeros limit artistas de a (ya ordenados por minutos) que b no escuchó
+func onlyIn(a, b []domain.ArtistRankingDTO, limit int) []domain.ArtistRankingDTO {
+	seen := make(map[string]bool, len(b))
+	for _, artist := range b {
+		seen[artistKey(artist.ArtistName)] = true
+	}
+	res := []domain.ArtistRankingDTO{}
+	for _, artist := range a {
+		if len(res) == limit {
+			break
+		}
+		if !seen[artistKey(artist.ArtistName)] {
+			res = append(res, artist)
+		}
+	}
+	return res
+}
+
+func roundTo(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
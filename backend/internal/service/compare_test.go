@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+func artist(name string, minutes float64, plays int) domain.ArtistRankingDTO {
+	a := domain.ArtistRankingDTO{ArtistName: name}
+	a.MinutesPlayed, a.TimesPlayed = minutes, plays
+	return a
+}
+
+func song(track, artist string, plays int) domain.SongRankingDTO {
+	s := domain.SongRankingDTO{TrackName: track, ArtistName: artist}
+	s.TimesPlayed, s.MinutesPlayed = plays, float64(plays)*3
+	return s
+}
+
+func TestCompareArtists(t *testing.T) {
+	a := []domain.ArtistRankingDTO{artist("Bad Bunny", 60, 20), artist("Mon Laferte", 30, 10), artist("Soda Stereo", 10, 4)}
+	b := []domain.ArtistRankingDTO{artist("mon laferte", 60, 15), artist("BAD BUNNY", 20, 8), artist("Queen", 20, 5)}
+
+	// Bad Bunny: min(0.6, 0.2) = 0.2; Mon Laferte: min(0.3, 0.6) = 0.3. Total 50%
+	compat, shared := compareArtists(a, b)
+	if compat != 50 {
+		t.Errorf("compatibilidad %v, se esperaba 50", compat)
+	}
+	want := []domain.SharedArtistDTO{
+		{ArtistName: "Mon Laferte", MinutesA: 30, MinutesB: 60, PlaysA: 10, PlaysB: 15, ShareA: 0.3, ShareB: 0.6},
+		{ArtistName: "Bad Bunny", MinutesA: 60, MinutesB: 20, PlaysA: 20, PlaysB: 8, ShareA: 0.6, ShareB: 0.2},
+	}
+	if !reflect.DeepEqual(shared, want) {
+		t.Errorf("compartidos:\n  %+v\nse esperaba:\n  %+v", shared, want)
+	}
+
+	if compat, _ := compareArtists(a, a); compat != 100 {
+		t.Errorf("un usuario consigo mismo: %v, se esperaba 100", compat)
+	}
+	if compat, shared := compareArtists(a, []domain.ArtistRankingDTO{artist("Queen", 5, 1)}); compat != 0 || len(shared) != 0 || shared == nil {
+		t.Errorf("sin artistas en común: %v %#v", compat, shared)
+	}
+	if compat, shared := compareArtists(nil, b); compat != 0 || shared == nil {
+		t.Errorf("historial vacío: %v %#v", compat, shared)
+	}
+	// Artistas compartidos sin minutos: no hay proporción que calcular
+	if compat, shared := compareArtists([]domain.ArtistRankingDTO{artist("X", 0, 1)}, []domain.ArtistRankingDTO{artist("x", 0, 1)}); compat != 0 || len(shared) != 0 {
+		t.Errorf("sin minutos: %v %+v", compat, shared)
+	}
+}
+
+func TestCompareTracks(t *testing.T) {
+	a := []domain.SongRankingDTO{song("Tití Me Preguntó", "Bad Bunny", 30), song("Amor Completo", "Mon Laferte", 10), song("Persiana Americana", "Soda Stereo", 12), song("Solo A", "X", 99)}
+	b := []domain.SongRankingDTO{song("AMOR COMPLETO", "mon laferte", 40), song("Tití me preguntó", "Bad Bunny", 10), song("Persiana Americana", "Soda Stereo", 10), song("Persiana Americana", "Otro", 50)}
+
+	got := compareTracks(a, b)
+	var order []string
+	for _, s := range got {
+		order = append(order, s.TrackName)
+	}
+	// Mínimo de reproducciones 10 en los tres; desempata la suma (50, 40, 22). El nombre es el de A
+	want := []string{"Amor Completo", "Tití Me Preguntó", "Persiana Americana"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("orden %q, se esperaba %q", order, want)
+	}
+	if got[0].PlaysA != 10 || got[0].PlaysB != 40 {
+		t.Errorf("reproducciones de la primera: %+v", got[0])
+	}
+	if got := compareTracks(a, nil); got == nil || len(got) != 0 {
+		t.Errorf("sin canciones en común: %#v", got)
+	}
+}
+
+func TestOnlyIn(t *testing.T) {
+	a := []domain.ArtistRankingDTO{artist("Bad Bunny", 60, 1), artist("Soda Stereo", 30, 1), artist("Queen", 20, 1), artist("Blur", 10, 1)}
+	b := []domain.ArtistRankingDTO{artist("BAD BUNNY", 5, 1), artist("blur", 5, 1)}
+
+	names := func(list []domain.ArtistRankingDTO) []string {
+		res := []string{}
+		for _, a := range list {
+			res = append(res, a.ArtistName)
+		}
+		return res
+	}
+	if got := names(onlyIn(a, b, 10)); !reflect.DeepEqual(got, []string{"Soda Stereo", "Queen"}) {
+		t.Errorf("onlyIn = %q", got)
+	}
+	if got := names(onlyIn(a, b, 1)); !reflect.DeepEqual(got, []string{"Soda Stereo"}) {
+		t.Errorf("onlyIn con límite 1 = %q", got)
+	}
+	if got := onlyIn(b, a, 10); got == nil || len(got) != 0 {
+		t.Errorf("todos compartidos: %#v", got)
+	}
+}
+
+// compareRepo entrega el mismo ranking para cualquier usuario y cuenta las consultas
+type compareRepo struct {
+	repository.SpotifyRepository
+	calls int
+}
+
+func (r *compareRepo) StreamTopArtists(ctx context.Context, f domain.SpotifyFilters, fn func(domain.ArtistRankingDTO) error) error {
+	r.calls++
+	return fn(artist("Bad Bunny", 10, 1))
+}
+
+func (r *compareRepo) StreamTopSongs(ctx context.Context, f domain.SpotifyFilters, fn func(domain.SongRankingDTO) error) error {
+	r.calls++
+	return fn(song("Tití Me Preguntó", "Bad Bunny", 1))
+}
+
+// compareUsers resuelve usernames desde un mapa y registra cuáles se consultaron
+type compareUsers struct {
+	repository.UserRepository
+	ids    map[string]int
+	lookup []string
+}
+
+func (u *compareUsers) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	u.lookup = append(u.lookup, username)
+	id, ok := u.ids[username]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	return domain.User{ID: id, Username: username}, nil
+}
+
+func TestCompareUsersAuthorization(t *testing.T) {
+	ana := domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}
+	admin := domain.Identity{UserID: 1, Username: "default", Role: domain.RoleAdmin}
+
+	tests := []struct {
+		name     string
+		caller   domain.Identity
+		a, b     string
+		wantKind domain.ErrorKind // Vacío si se espera éxito
+		lookups  bool             // Si se llegó a consultar los usuarios
+	}{
+		{name: "reader compara su historial", caller: ana, a: "ana", b: "luis", lookups: true},
+		{name: "reader como segundo usuario", caller: ana, a: "luis", b: "ana", lookups: true},
+		{name: "reader entre otros dos usuarios", caller: ana, a: "luis", b: "eva", wantKind: domain.ErrKindForbidden},
+		{name: "reader con un usuario inexistente ajeno", caller: ana, a: "luis", b: "nadie", wantKind: domain.ErrKindForbidden},
+		{name: "reader con dos usuarios inexistentes", caller: ana, a: "nadie", b: "tampoco", wantKind: domain.ErrKindForbidden},
+		{name: "reader contra un usuario inexistente", caller: ana, a: "ana", b: "nadie", wantKind: domain.ErrKindNotFound, lookups: true},
+		{name: "admin entre otros dos usuarios", caller: admin, a: "luis", b: "eva", lookups: true},
+		{name: "admin con un usuario inexistente", caller: admin, a: "luis", b: "nadie", wantKind: domain.ErrKindNotFound, lookups: true},
+		{name: "sin identidad", caller: domain.Identity{}, a: "luis", b: "eva", wantKind: domain.ErrKindForbidden},
+		{name: "falta b", caller: ana, a: "ana", b: " ", wantKind: domain.ErrKindValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &compareUsers{ids: map[string]int{"ana": 2, "luis": 3, "eva": 4}}
+			svc := NewSpotifyService(&compareRepo{}, users)
+			ctx := context.Background()
+			if tt.caller.UserID != 0 {
+				ctx = domain.WithIdentity(ctx, tt.caller)
+			}
+
+			res, err := svc.CompareUsers(ctx, tt.a, tt.b, domain.SpotifyFilters{})
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Compatibility != 100 || res.SharedTrackCount != 1 {
+					t.Errorf("resultado inesperado: %+v", res)
+				}
+			} else {
+				var appErr *domain.AppError
+				if !errors.As(err, &appErr) || appErr.Kind != tt.wantKind {
+					t.Fatalf("error %v, se esperaba %s", err, tt.wantKind)
+				}
+			}
+			if (len(users.lookup) > 0) != tt.lookups {
+				t.Errorf("usuarios consultados: %q", users.lookup)
+			}
+		})
+	}
+}
+
+// Un reader no ve los minutos, reproducciones ni artistas exclusivos del otro usuario: nadie
+// consintió en compartirlos. Un admin ve ambos lados
+func TestCompareUsersHidesOtherUser(t *testing.T) {
+	ana := domain.Identity{UserID: 2, Username: "ana", Role: domain.RoleReader}
+	admin := domain.Identity{UserID: 1, Username: "default", Role: domain.RoleAdmin}
+
+	tests := []struct {
+		name            string
+		caller          domain.Identity
+		a, b            string
+		visible, hidden []string // Claves JSON presentes y ausentes
+	}{
+		{name: "reader como a", caller: ana, a: "ana", b: "luis",
+			visible: []string{`"minutes_a"`, `"plays_a"`, `"share_a"`, `"only_a":[]`, `"only_b":null`},
+			hidden:  []string{`"minutes_b"`, `"plays_b"`, `"share_b"`}},
+		{name: "reader como b", caller: ana, a: "luis", b: "ana",
+			visible: []string{`"minutes_b"`, `"plays_b"`, `"share_b"`, `"only_b":[]`, `"only_a":null`},
+			hidden:  []string{`"minutes_a"`, `"plays_a"`, `"share_a"`}},
+		{name: "admin", caller: admin, a: "luis", b: "eva",
+			visible: []string{`"minutes_a"`, `"minutes_b"`, `"plays_a"`, `"plays_b"`, `"only_a":[]`, `"only_b":[]`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &compareUsers{ids: map[string]int{"ana": 2, "luis": 3, "eva": 4}}
+			ctx := domain.WithIdentity(context.Background(), tt.caller)
+			res, err := NewSpotifyService(&compareRepo{}, users).CompareUsers(ctx, tt.a, tt.b, domain.SpotifyFilters{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// La compatibilidad y los nombres en común se mantienen
+			if res.Compatibility != 100 || len(res.SharedArtists) != 1 || res.TopSharedTrack == nil || res.TopSharedTrack.TrackName != "Tití Me Preguntó" {
+				t.Errorf("resultado inesperado: %+v", res)
+			}
+			body, _ := json.Marshal(res)
+			for _, key := range tt.visible {
+				if !strings.Contains(string(body), key) {
+					t.Errorf("falta %s en %s", key, body)
+				}
+			}
+			for _, key := range tt.hidden {
+				if strings.Contains(string(body), key) {
+					t.Errorf("%s visible en %s", key, body)
+				}
+			}
+		})
+	}
+}
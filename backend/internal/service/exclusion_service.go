@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+type ExclusionService interface {
+	List(ctx context.Context) ([]domain.ExcludedEntity, error)
+	Get(ctx context.Context, id int) (domain.ExcludedEntity, error)
+	Create(ctx context.Context, in domain.ExcludedEntityInput) (domain.ExcludedEntity, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type exclusionService struct {
+	repo repository.ExclusionRepository
+}
+
+func NewExclusionService(repo repository.ExclusionRepository) ExclusionService {
+	return &exclusionService{repo: repo}
+}
+
+func (s *exclusionService) List(ctx context.Context) ([]domain.ExcludedEntity, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *exclusionService) Get(ctx context.Context, id int) (domain.ExcludedEntity, error) {
+	e, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return e, domain.NewNotFoundError("No existe la exclusión %d", id)
+	}
+	return e, err
+}
+
+func (s *exclusionService) Create(ctx context.Context, in domain.ExcludedEntityInput) (domain.ExcludedEntity, error) {
+	in.Clean()
+	if errs := in.Validate(); len(errs) > 0 {
+		return domain.ExcludedEntity{}, domain.NewFieldsValidationError(errs)
+	}
+	e, err := s.repo.Create(ctx, in)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return e, domain.NewValidationError("La entidad ya está excluida")
+	}
+	return e, err
+}
+
+func (s *exclusionService) Delete(ctx context.Context, id int) error {
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.NewNotFoundError("No existe la exclusión %d", id)
+	}
+	return err
+}
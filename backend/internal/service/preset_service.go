@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+type PresetService interface {
+	List(ctx context.Context) ([]domain.FilterPreset, error)
+	Get(ctx context.Context, id int) (domain.FilterPreset, error)
+	Create(ctx context.Context, in domain.FilterPresetInput) (domain.FilterPreset, error)
+	Update(ctx context.Context, id int, in domain.FilterPresetInput) (domain.FilterPreset, error)
+	Delete(ctx context.Context, id int) error
+	// Resolve obtiene los parámetros del preset indicado en ?preset=
+	Resolve(ctx context.Context, name string) (domain.PresetParams, error)
+}
+
+type presetService struct {
+	repo repository.PresetRepository
+}
+
+func NewPresetService(repo repository.PresetRepository) PresetService {
+	return &presetService{repo: repo}
+}
+
+func (s *presetService) List(ctx context.Context) ([]domain.FilterPreset, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *presetService) Get(ctx context.Context, id int) (domain.FilterPreset, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	return p, presetError(err, "No existe el preset %d", id)
+}
+
+// Create y Update esperan parámetros ya validados por el handler, que conoce la sintaxis de la query
+func (s *presetService) Create(ctx context.Context, in domain.FilterPresetInput) (domain.FilterPreset, error) {
+	in.Clean()
+	if errs := in.Validate(); len(errs) > 0 {
+		return domain.FilterPreset{}, domain.NewFieldsValidationError(errs)
+	}
+	p, err := s.repo.Create(ctx, in)
+	return p, presetError(err, "Ya existe un preset llamado %q", in.Name)
+}
+
+func (s *presetService) Update(ctx context.Context, id int, in domain.FilterPresetInput) (domain.FilterPreset, error) {
+	in.Clean()
+	if errs := in.Validate(); len(errs) > 0 {
+		return domain.FilterPreset{}, domain.NewFieldsValidationError(errs)
+	}
+	p, err := s.repo.Update(ctx, id, in)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return p, domain.NewValidationError("Ya existe un preset llamado %q", in.Name)
+	}
+	return p, presetError(err, "No existe el preset %d", id)
+}
+
+func (s *presetService) Delete(ctx context.Context, id int) error {
+	return presetError(s.repo.Delete(ctx, id), "No existe el preset %d", id)
+}
+
+func (s *presetService) Resolve(ctx context.Context, name string) (domain.PresetParams, error) {
+	p, err := s.repo.GetByName(ctx, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, domain.NewFieldsValidationError([]domain.FieldError{{Field: "preset", Reason: "no existe un preset con ese nombre"}})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.Params, nil
+}
+
+// presetError traduce los errores del repositorio al caso que puede ocurrir en cada
+// operación: no encontrado en Get/Delete o nombre duplicado en Create
+func presetError(err error, format string, args ...interface{}) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return domain.NewNotFoundError(format, args...)
+	case errors.Is(err, repository.ErrDuplicate):
+		return domain.NewValidationError(format, args...)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// Bytes aleatorios del token (256 bits, 43 caracteres en base64url)
+const shareTokenBytes = 32
+
+type ShareService interface {
+	List(ctx context.Context) ([]domain.ShareLink, error)
+	// GetLink retorna los datos de un enlace propio, sin el token
+	GetLink(ctx context.Context, id int) (domain.ShareLink, error)
+	// Create congela el wrapped y retorna el enlace con su token, que no se vuelve a mostrar
+	Create(ctx context.Context, wrapped domain.WrappedDTO, expiresInHours int) (domain.ShareLink, error)
+	Revoke(ctx context.Context, id int) error
+	Get(ctx context.Context, token string) (domain.SharedWrappedDTO, error)
+}
+
+type shareService struct {
+	repo repository.ShareRepository
+}
+
+func NewShareService(repo repository.ShareRepository) ShareService {
+	return &shareService{repo: repo}
+}
+
+func (s *shareService) List(ctx context.Context) ([]domain.ShareLink, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *shareService) GetLink(ctx context.Context, id int) (domain.ShareLink, error) {
+	link, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return link, domain.NewNotFoundError("No existe el enlace %d", id)
+	}
+	return link, err
+}
+
+func (s *shareService) Create(ctx context.Context, wrapped domain.WrappedDTO, expiresInHours int) (domain.ShareLink, error) {
+	if expiresInHours < 0 || expiresInHours > domain.MaxShareExpiryHours {
+		return domain.ShareLink{}, domain.NewFieldsValidationError([]domain.FieldError{
+			{Field: "expires_in", Reason: fmt.Sprintf("debe estar entre 1 y %d horas (0 = sin expiración)", domain.MaxShareExpiryHours)},
+		})
+	}
+	var expiresAt *time.Time
+	if expiresInHours > 0 {
+		t := time.Now().Add(time.Duration(expiresInHours) * time.Hour)
+		expiresAt = &t
+	}
+
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return domain.ShareLink{}, domain.NewInternalError(err, "No se pudo generar el enlace")
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link, err := s.repo.Create(ctx, hashShareToken(token), wrapped, expiresAt)
+	if err != nil {
+		return link, err
+	}
+	link.Token = token
+	link.URL = "/share/" + token
+	return link, nil
+}
+
+func (s *shareService) Revoke(ctx context.Context, id int) error {
+	err := s.repo.Revoke(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.NewNotFoundError("No existe el enlace %d o ya fue revocado", id)
+	}
+	return err
+}
+
+// Get no distingue entre token inexistente, expirado o revocado para no dar pistas a quien adivina
+func (s *shareService) Get(ctx context.Context, token string) (domain.SharedWrappedDTO, error) {
+	res, err := s.repo.GetActive(ctx, hashShareToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return res, domain.NewNotFoundError("El enlace no existe o ya no está disponible")
+	}
+	return res, err
+}
+
+func hashShareToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
 	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
@@ -11,22 +13,39 @@ import (
 
 type SpotifyService interface {
 	GetDashboardStats(ctx context.Context, f domain.SpotifyFilters) (domain.TotalStatsDTO, error)
-	GetTopList(ctx context.Context, listType string, f domain.SpotifyFilters) (interface{}, error)
+	GetTopArtists(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.ArtistRankingDTO], error)
+	GetTopSongs(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.SongRankingDTO], error)
+	GetTopAlbums(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.AlbumRankingDTO], error)
 	GetHabitAnalysis(ctx context.Context, habitType string, f domain.SpotifyFilters) ([]domain.HabitTimeDTO, error)
 	GetGlobalEvolution(ctx context.Context, f domain.SpotifyFilters) ([]domain.HistoryEvolutionDTO, error)
-	SearchRankedItem(ctx context.Context, f domain.SpotifyFilters, target domain.ArtistTrackFilters, limit int) (interface{}, error)
+	SearchRankedItem(ctx context.Context, f domain.SpotifyFilters, target domain.ArtistTrackFilters, limit int) (domain.RankSearchResultDTO, error)
 	GetYearlyStats(ctx context.Context, f domain.SpotifyFilters) ([]domain.YearlyStatsDTO, error)
-	GetYearlyWrapped(ctx context.Context, year int) (interface{}, error)
-	GetMonthlyWrapped(ctx context.Context, year, month int) (interface{}, error)
-	GetSeasonalWrapped(ctx context.Context, year int, season domain.Season) (interface{}, error)
+	GetYearlyWrapped(ctx context.Context, year int, f domain.SpotifyFilters) (domain.WrappedDTO, error)
+	GetMonthlyWrapped(ctx context.Context, year, month int, f domain.SpotifyFilters) (domain.WrappedDTO, error)
+	GetSeasonalWrapped(ctx context.Context, year int, season domain.Season, f domain.SpotifyFilters) (domain.WrappedDTO, error)
+	// GetWrappedTopSongs es solo el top de canciones del periodo (contrato de /api/v1), sin el resto del wrapped
+	GetWrappedTopSongs(ctx context.Context, year, month int, season domain.Season, f domain.SpotifyFilters) ([]domain.SongRankingDTO, error)
+	GetDiversity(ctx context.Context, f domain.SpotifyFilters) (domain.DiversityDTO, error)
+	GetBinges(ctx context.Context, f domain.SpotifyFilters, b domain.BingeFilters) (domain.Pagination[domain.BingeDTO], error)
+	GetCountryAnalytics(ctx context.Context, f domain.SpotifyFilters) (domain.CountryAnalyticsDTO, error)
+	GetPlatformAnalytics(ctx context.Context, f domain.SpotifyFilters) (domain.PlatformAnalyticsDTO, error)
+	GetListeningSources(ctx context.Context, f domain.SpotifyFilters) (domain.ListeningSourcesDTO, error)
+	GetOfflineStats(ctx context.Context, f domain.SpotifyFilters) (domain.OfflineStatsDTO, error)
+	GetIncognitoStats(ctx context.Context, f domain.SpotifyFilters) (domain.IncognitoStatsDTO, error)
+	Export(ctx context.Context, dataset domain.ExportDataset, f domain.SpotifyFilters, fn func(domain.ExportRow) error) error
+	GetPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage) (domain.CursorPagination[domain.SpotifyRecord], error)
+	ExportPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage, fn func(domain.ExportRow) error) error
+	Search(ctx context.Context, term string, f domain.SpotifyFilters) (domain.SearchResultDTO, error)
+	CompareUsers(ctx context.Context, usernameA, usernameB string, f domain.SpotifyFilters) (domain.UserComparisonDTO, error)
 }
 
 type spotifyService struct {
-	repo repository.SpotifyRepository
+	repo  repository.SpotifyRepository
+	users repository.UserRepository
 }
 
-func NewSpotifyService(repo repository.SpotifyRepository) SpotifyService {
-	return &spotifyService{repo: repo}
+func NewSpotifyService(repo repository.SpotifyRepository, users repository.UserRepository) SpotifyService {
+	return &spotifyService{repo: repo, users: users}
 }
 
 // Implementación de SpotifyService
@@ -36,25 +55,29 @@ func (s *spotifyService) GetDashboardStats(ctx context.Context, f domain.Spotify
 	return s.repo.GetTotalStats(ctx, f)
 }
 
-func (s *spotifyService) GetTopList(ctx context.Context, listType string, f domain.SpotifyFilters) (interface{}, error) {
+func (s *spotifyService) GetTopArtists(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.ArtistRankingDTO], error) {
 	f.CleanAndValidate()
-	var data interface{}
-	var total int
-	var err error
+	data, total, err := s.repo.GetTopArtists(ctx, f)
+	if err != nil {
+		return domain.Pagination[domain.ArtistRankingDTO]{}, err
+	}
+	return domain.NewPagination(data, total, f.Page, f.Limit), nil
+}
 
-	switch listType {
-	case "artists":
-		data, total, err = s.repo.GetTopArtists(ctx, f)
-	case "songs":
-		data, total, err = s.repo.GetTopSongs(ctx, f)
-	case "albums":
-		data, total, err = s.repo.GetTopAlbums(ctx, f)
-	default:
-		return nil, nil
+func (s *spotifyService) GetTopSongs(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.SongRankingDTO], error) {
+	f.CleanAndValidate()
+	data, total, err := s.repo.GetTopSongs(ctx, f)
+	if err != nil {
+		return domain.Pagination[domain.SongRankingDTO]{}, err
 	}
+	return domain.NewPagination(data, total, f.Page, f.Limit), nil
+}
 
+func (s *spotifyService) GetTopAlbums(ctx context.Context, f domain.SpotifyFilters) (domain.Pagination[domain.AlbumRankingDTO], error) {
+	f.CleanAndValidate()
+	data, total, err := s.repo.GetTopAlbums(ctx, f)
 	if err != nil {
-		return domain.Pagination{}, err
+		return domain.Pagination[domain.AlbumRankingDTO]{}, err
 	}
 	return domain.NewPagination(data, total, f.Page, f.Limit), nil
 }
@@ -78,53 +101,312 @@ func (s *spotifyService) GetGlobalEvolution(ctx context.Context, f domain.Spotif
 	return s.repo.GetHistoryEvolution(ctx, f)
 }
 
+// Export entrega fila a fila el listado pedido, tal como llega desde la base de datos.
+// Con f.Unpaged se exporta el listado completo ignorando page y limit
+func (s *spotifyService) Export(ctx context.Context, dataset domain.ExportDataset, f domain.SpotifyFilters, fn func(domain.ExportRow) error) error {
+	f.CleanAndValidate()
+	switch dataset {
+	case domain.ExportTopArtists:
+		return s.repo.StreamTopArtists(ctx, f, exportRow[domain.ArtistRankingDTO](fn))
+	case domain.ExportTopSongs:
+		return s.repo.StreamTopSongs(ctx, f, exportRow[domain.SongRankingDTO](fn))
+	case domain.ExportTopAlbums:
+		return s.repo.StreamTopAlbums(ctx, f, exportRow[domain.AlbumRankingDTO](fn))
+	case domain.ExportHabitsTime:
+		return s.repo.StreamHabitsByTimeOfDay(ctx, f, exportRow[domain.HabitTimeDTO](fn))
+	case domain.ExportHabitsDow:
+		return s.repo.StreamHabitsByDayOfWeek(ctx, f, exportRow[domain.HabitTimeDTO](fn))
+	case domain.ExportEvolution:
+		return s.repo.StreamHistoryEvolution(ctx, f, exportRow[domain.HistoryEvolutionDTO](fn))
+	case domain.ExportYearly:
+		return s.repo.StreamYearlyStats(ctx, f, exportRow[domain.YearlyStatsDTO](fn))
+	}
+	return domain.NewValidationError("Conjunto de datos no exportable: %s", dataset)
+}
+
+// GetPlays lista reproducciones individuales. Se pide una fila extra para saber si hay
+// otra página sin contar el total, que obligaría a recorrer todo el historial filtrado
+func (s *spotifyService) GetPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage) (domain.CursorPagination[domain.SpotifyRecord], error) {
+	f.CleanAndValidate()
+	p.Limit = f.Limit
+	p.Clean()
+
+	limit := p.Limit
+	p.Limit++
+	plays, err := s.repo.GetPlays(ctx, f, p)
+	if err != nil {
+		return domain.CursorPagination[domain.SpotifyRecord]{}, err
+	}
+
+	res := domain.CursorPagination[domain.SpotifyRecord]{Limit: limit, Data: plays}
+	if len(plays) > limit {
+		last := plays[limit-1]
+		res.Data = plays[:limit]
+		res.HasMore = true
+		res.NextCursor = domain.PlayCursor{TS: last.TS, ID: last.ID}.Encode()
+	}
+	return res, nil
+}
+
+// ExportPlays transmite las reproducciones desde el cursor indicado. p.Limit = 0 exporta todo
+func (s *spotifyService) ExportPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage, fn func(domain.ExportRow) error) error {
+	f.CleanAndValidate()
+	p.Clean()
+	return s.repo.StreamPlays(ctx, f, p, exportRow[domain.SpotifyRecord](fn))
+}
+
+// exportRow adapta un callback de filas genéricas al tipo concreto que entrega el repositorio
+func exportRow[T domain.ExportRow](fn func(domain.ExportRow) error) func(T) error {
+	return func(item T) error { return fn(item) }
+}
+
 // SearchRankedItem permite buscar dónde quedó un artista o canción específica en el ranking global
-func (s *spotifyService) SearchRankedItem(ctx context.Context, f domain.SpotifyFilters, target domain.ArtistTrackFilters, limit int) (interface{}, error) {
+func (s *spotifyService) SearchRankedItem(ctx context.Context, f domain.SpotifyFilters, target domain.ArtistTrackFilters, limit int) (domain.RankSearchResultDTO, error) {
 	f.CleanAndValidate()
 	target.Clean()
 
 	if limit <= 0 {
 		limit = 10
 	}
+	if limit > domain.MaxLimit {
+		limit = domain.MaxLimit
+	}
 
+	var res domain.RankSearchResultDTO
+	var err error
 	if target.Track != "" {
-		return s.repo.GetRankedSongs(ctx, f, target, limit)
+		res.Type = "song"
+		res.Songs, err = s.repo.GetRankedSongs(ctx, f, target, limit)
+		if res.Songs == nil {
+			res.Songs = []domain.SongRankingDTO{}
+		}
+		return res, err
+	}
+	res.Type = "artist"
+	res.Artists, err = s.repo.GetRankedArtist(ctx, f, target, limit)
+	if res.Artists == nil {
+		res.Artists = []domain.ArtistRankingDTO{}
+	}
+	return res, err
+}
+
+// GetDiversity calcula entropía, Gini y concentración para artistas y canciones, en total y mes a mes
+func (s *spotifyService) GetDiversity(ctx context.Context, f domain.SpotifyFilters) (domain.DiversityDTO, error) {
+	f.CleanAndValidate()
+	var res domain.DiversityDTO
+	var err error
+
+	if res.Artists, err = s.repo.GetDiversityMetrics(ctx, f, domain.DiversityByArtist); err != nil {
+		return res, err
+	}
+	if res.Tracks, err = s.repo.GetDiversityMetrics(ctx, f, domain.DiversityByTrack); err != nil {
+		return res, err
+	}
+
+	artistsMonthly, err := s.repo.GetMonthlyDiversity(ctx, f, domain.DiversityByArtist)
+	if err != nil {
+		return res, err
+	}
+	tracksMonthly, err := s.repo.GetMonthlyDiversity(ctx, f, domain.DiversityByTrack)
+	if err != nil {
+		return res, err
+	}
+
+	// Ambas series cubren los mismos meses (mismos filtros), se combinan por YYYY-MM
+	tracksByMonth := make(map[string]domain.DiversityMetricsDTO, len(tracksMonthly))
+	for _, m := range tracksMonthly {
+		tracksByMonth[m.YearMonth] = m.Tracks
+	}
+	res.Monthly = make([]domain.MonthlyDiversityDTO, 0, len(artistsMonthly))
+	for _, m := range artistsMonthly {
+		m.Tracks = tracksByMonth[m.YearMonth]
+		res.Monthly = append(res.Monthly, m)
+	}
+	return res, nil
+}
+
+// GetBinges retorna las rachas más largas de una misma canción o artista sonando en bucle
+func (s *spotifyService) GetBinges(ctx context.Context, f domain.SpotifyFilters, b domain.BingeFilters) (domain.Pagination[domain.BingeDTO], error) {
+	f.CleanAndValidate()
+	b.Clean()
+
+	data, total, err := s.repo.GetBinges(ctx, f, b)
+	if err != nil {
+		return domain.Pagination[domain.BingeDTO]{}, err
+	}
+	return domain.NewPagination(data, total, f.Page, f.Limit), nil
+}
+
+// GetCountryAnalytics combina consumo por país, top artistas de cada uno (f.Limit) y la línea de tiempo de viajes
+func (s *spotifyService) GetCountryAnalytics(ctx context.Context, f domain.SpotifyFilters) (domain.CountryAnalyticsDTO, error) {
+	f.CleanAndValidate()
+	res := domain.CountryAnalyticsDTO{
+		Countries: []domain.CountryStatsDTO{},
+		Trips:     []domain.CountryTripDTO{},
+	}
+
+	countries, err := s.repo.GetCountryStats(ctx, f)
+	if err != nil {
+		return res, err
+	}
+	topArtists, err := s.repo.GetTopArtistsByCountry(ctx, f, f.Limit)
+	if err != nil {
+		return res, err
+	}
+	for _, c := range countries {
+		c.TopArtists = topArtists[c.Country]
+		if c.TopArtists == nil {
+			c.TopArtists = []domain.ArtistRankingDTO{}
+		}
+		res.Countries = append(res.Countries, c)
+	}
+
+	trips, err := s.repo.GetCountryTimeline(ctx, f)
+	if err != nil {
+		return res, err
+	}
+	if trips != nil {
+		res.Trips = trips
+	}
+	return res, nil
+}
+
+// GetPlatformAnalytics retorna el consumo por familia de dispositivo, total y mensual
+func (s *spotifyService) GetPlatformAnalytics(ctx context.Context, f domain.SpotifyFilters) (domain.PlatformAnalyticsDTO, error) {
+	f.CleanAndValidate()
+	res := domain.PlatformAnalyticsDTO{
+		Families:  []domain.PlatformStatsDTO{},
+		Evolution: []domain.PlatformEvolutionDTO{},
+	}
+
+	families, err := s.repo.GetPlatformStats(ctx, f)
+	if err != nil {
+		return res, err
+	}
+	evolution, err := s.repo.GetPlatformEvolution(ctx, f)
+	if err != nil {
+		return res, err
+	}
+
+	if families != nil {
+		res.Families = families
+	}
+	if evolution != nil {
+		res.Evolution = evolution
+	}
+	return res, nil
+}
+
+// GetListeningSources indica qué parte de lo escuchado fue elegido (click) y qué parte servido (shuffle, autoplay),
+// en total y para cada artista paginado por reproducciones
+func (s *spotifyService) GetListeningSources(ctx context.Context, f domain.SpotifyFilters) (domain.ListeningSourcesDTO, error) {
+	f.CleanAndValidate()
+	var res domain.ListeningSourcesDTO
+
+	overall, err := s.repo.GetSourceBreakdown(ctx, f)
+	if err != nil {
+		return res, err
+	}
+	artists, total, err := s.repo.GetArtistSourceBreakdown(ctx, f)
+	if err != nil {
+		return res, err
+	}
+
+	res.Overall = overall
+	res.Artists = domain.NewPagination(artists, total, f.Page, f.Limit)
+	return res, nil
+}
+
+func (s *spotifyService) GetOfflineStats(ctx context.Context, f domain.SpotifyFilters) (domain.OfflineStatsDTO, error) {
+	f.CleanAndValidate()
+	return s.repo.GetOfflineStats(ctx, f)
+}
+
+// GetIncognitoStats ignora exclude_incognito, que contradice el propio análisis
+func (s *spotifyService) GetIncognitoStats(ctx context.Context, f domain.SpotifyFilters) (domain.IncognitoStatsDTO, error) {
+	f.CleanAndValidate()
+	f.ExcludeIncognito = false
+
+	res, err := s.repo.GetIncognitoStats(ctx, f)
+	if err != nil {
+		return res, err
+	}
+
+	f.IncognitoOnly = true
+	res.TopArtists, _, err = s.repo.GetTopArtists(ctx, f)
+	return res, err
+}
+
+// Metodos para obtener wrappeds segun el año, mes o estacion.
+// f aporta los filtros base (ej. exclude_incognito), el periodo y la paginación los define el wrapped
+func (s *spotifyService) GetYearlyWrapped(ctx context.Context, year int, f domain.SpotifyFilters) (domain.WrappedDTO, error) {
+	return s.buildWrapped(ctx, yearlyPeriod(year), f)
+}
+
+func (s *spotifyService) GetMonthlyWrapped(ctx context.Context, year, month int, f domain.SpotifyFilters) (domain.WrappedDTO, error) {
+	period, err := monthlyPeriod(year, month)
+	if err != nil {
+		return domain.WrappedDTO{}, err
+	}
+	return s.buildWrapped(ctx, period, f)
+}
+
+func (s *spotifyService) GetSeasonalWrapped(ctx context.Context, year int, season domain.Season, f domain.SpotifyFilters) (domain.WrappedDTO, error) {
+	period, err := seasonalPeriod(year, season)
+	if err != nil {
+		return domain.WrappedDTO{}, err
+	}
+	return s.buildWrapped(ctx, period, f)
+}
+
+// GetWrappedTopSongs elige el periodo igual que el handler: season tiene prioridad sobre month,
+// y sin ninguno es el año completo. Hace una sola consulta en vez de las cinco de buildWrapped
+func (s *spotifyService) GetWrappedTopSongs(ctx context.Context, year, month int, season domain.Season, f domain.SpotifyFilters) ([]domain.SongRankingDTO, error) {
+	period := yearlyPeriod(year)
+	var err error
+	switch {
+	case season != "":
+		period, err = seasonalPeriod(year, season)
+	case month != 0:
+		period, err = monthlyPeriod(year, month)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.GetRankedArtist(ctx, f, target, limit)
+	songs, _, err := s.repo.GetTopSongs(ctx, wrappedFilters(period, f))
+	if songs == nil {
+		songs = []domain.SongRankingDTO{}
+	}
+	return songs, err
 }
 
-// Metodos para obtener wrappeds segun el año, mes o estacion
-func (s *spotifyService) GetYearlyWrapped(ctx context.Context, year int) (interface{}, error) {
+func yearlyPeriod(year int) domain.WrappedPeriodDTO {
 	loc, _ := time.LoadLocation("America/Santiago")
 	start := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
 	end := start.AddDate(1, 0, 0).Add(-time.Second)
 
-	f := domain.SpotifyFilters{StartDate: &start, EndDate: &end, Limit: 100, Page: 1}
-	songs, _, err := s.repo.GetTopSongs(ctx, f)
-	return songs, err
+	return domain.WrappedPeriodDTO{Type: domain.WrappedYear, Year: year, StartDate: start, EndDate: end}
 }
 
-func (s *spotifyService) GetMonthlyWrapped(ctx context.Context, year, month int) (interface{}, error) {
+func monthlyPeriod(year, month int) (domain.WrappedPeriodDTO, error) {
 	if month < 1 || month > 12 {
-		return nil, fmt.Errorf("el mes %d no es válido (debe ser 1-12)", month)
+		return domain.WrappedPeriodDTO{}, domain.NewValidationError("el mes %d no es válido (debe ser 1-12)", month)
 	}
 
 	loc, _ := time.LoadLocation("America/Santiago")
 	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 
-	f := domain.SpotifyFilters{StartDate: &start, EndDate: &end, Limit: 100, Page: 1}
-	songs, _, err := s.repo.GetTopSongs(ctx, f)
-	return songs, err
+	return domain.WrappedPeriodDTO{Type: domain.WrappedMonth, Year: year, Month: month, StartDate: start, EndDate: end}, nil
 }
 
-func (s *spotifyService) GetSeasonalWrapped(ctx context.Context, year int, season domain.Season) (interface{}, error) {
+func seasonalPeriod(year int, season domain.Season) (domain.WrappedPeriodDTO, error) {
 	validSeasons := map[domain.Season]bool{
 		domain.Summer: true, domain.Autumn: true,
 		domain.Winter: true, domain.Spring: true,
 	}
 	if !validSeasons[season] {
-		return nil, fmt.Errorf("estación '%s' no válida. Use: summer, autumn, winter o spring", season)
+		return domain.WrappedPeriodDTO{}, domain.NewValidationError("estación '%s' no válida. Use: summer, autumn, winter o spring", season)
 	}
 
 	loc, _ := time.LoadLocation("America/Santiago")
@@ -145,7 +427,86 @@ func (s *spotifyService) GetSeasonalWrapped(ctx context.Context, year int, seaso
 		end = time.Date(year, 12, 20, 23, 59, 59, 0, loc)
 	}
 
-	f := domain.SpotifyFilters{StartDate: &start, EndDate: &end, Limit: 100, Page: 1}
-	songs, _, err := s.repo.GetTopSongs(ctx, f)
-	return songs, err
+	return domain.WrappedPeriodDTO{Type: domain.WrappedSeason, Year: year, Season: season, StartDate: start, EndDate: end}, nil
+}
+
+// Cantidad de items en cada top del wrapped
+const wrappedTopLimit = 100
+
+// buildWrapped aplica el periodo del wrapped sobre los filtros base y arma el resumen completo
+func (s *spotifyService) buildWrapped(ctx context.Context, period domain.WrappedPeriodDTO, f domain.SpotifyFilters) (domain.WrappedDTO, error) {
+	f = wrappedFilters(period, f)
+	res := domain.WrappedDTO{Period: period}
+	var err error
+
+	if res.Stats, err = s.repo.GetTotalStats(ctx, f); err != nil {
+		return res, err
+	}
+	if res.TopSongs, _, err = s.repo.GetTopSongs(ctx, f); err != nil {
+		return res, err
+	}
+	if res.TopArtists, _, err = s.repo.GetTopArtists(ctx, f); err != nil {
+		return res, err
+	}
+	if res.TopAlbums, _, err = s.repo.GetTopAlbums(ctx, f); err != nil {
+		return res, err
+	}
+	if res.TimeOfDay, err = s.repo.GetHabitsByTimeOfDay(ctx, f); err != nil {
+		return res, err
+	}
+	if res.TimeOfDay == nil {
+		res.TimeOfDay = []domain.HabitTimeDTO{}
+	}
+	return res, nil
+}
+
+// wrappedFilters aplica el periodo del wrapped y su paginación sobre los filtros base
+func wrappedFilters(period domain.WrappedPeriodDTO, f domain.SpotifyFilters) domain.SpotifyFilters {
+	f.CleanAndValidate()
+	f.StartDate, f.EndDate = &period.StartDate, &period.EndDate
+	f.Limit, f.Page = wrappedTopLimit, 1
+	return f
+}
+
+// Search busca artistas, álbumes y canciones parecidos al término. f.Limit aplica a cada grupo
+func (s *spotifyService) Search(ctx context.Context, term string, f domain.SpotifyFilters) (domain.SearchResultDTO, error) {
+	f.CleanAndValidate()
+	term = strings.TrimSpace(term)
+	if utf8.RuneCountInString(term) < domain.MinSearchLength {
+		return domain.SearchResultDTO{}, domain.NewFieldsValidationError([]domain.FieldError{
+			{Field: "q", Reason: fmt.Sprintf("debe tener al menos %d caracteres", domain.MinSearchLength)},
+		})
+	}
+
+	res := domain.SearchResultDTO{Query: term}
+	groups := []struct {
+		kind domain.SearchKind
+		dest *[]domain.SearchHitDTO
+	}{
+		{domain.SearchArtists, &res.Artists},
+		{domain.SearchAlbums, &res.Albums},
+		{domain.SearchTracks, &res.Tracks},
+	}
+	for _, g := range groups {
+		hits, err := s.repo.SearchEntities(ctx, f, g.kind, term, f.Limit)
+		if err != nil {
+			return domain.SearchResultDTO{}, err
+		}
+		*g.dest = hits
+	}
+	return res, nil
+}
+
+// SplitByDayType ejecuta la misma consulta para días hábiles y fin de semana (split=day_type).
+// Es una función y no un método porque Go no admite métodos genéricos
+func SplitByDayType[T any](f domain.SpotifyFilters, fetch func(domain.SpotifyFilters) (T, error)) (domain.DayTypeSplit[T], error) {
+	var res domain.DayTypeSplit[T]
+	var err error
+	if res.Weekday, err = fetch(f.WithWeekdays(domain.WorkingDays)); err != nil {
+		return res, err
+	}
+	if res.Weekend, err = fetch(f.WithWeekdays(domain.WeekendDays)); err != nil {
+		return res, err
+	}
+	return res, nil
 }
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// playsRepo simula la consulta por keyset: reproducciones ordenadas por (ts, id) descendente,
+// estrictamente después del cursor y hasta p.Limit filas
+type playsRepo struct {
+	repository.SpotifyRepository
+	plays  []domain.SpotifyRecord
+	limits []int // p.Limit recibido en cada consulta
+}
+
+func (r *playsRepo) GetPlays(ctx context.Context, f domain.SpotifyFilters, p domain.PlayPage) ([]domain.SpotifyRecord, error) {
+	r.limits = append(r.limits, p.Limit)
+	res := []domain.SpotifyRecord{}
+	for _, play := range r.plays {
+		if p.After != nil && !before(play, *p.After) {
+			continue
+		}
+		if len(res) == p.Limit {
+			break
+		}
+		res = append(res, play)
+	}
+	return res, nil
+}
+
+func before(play domain.SpotifyRecord, c domain.PlayCursor) bool {
+	return play.TS.Before(c.TS) || (play.TS.Equal(c.TS) && play.ID < c.ID)
+}
+
+// newPlays genera n reproducciones en orden descendente; las dos primeras comparten ts
+func newPlays(n int) []domain.SpotifyRecord {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	plays := make([]domain.SpotifyRecord, n)
+	for i := range plays {
+		ts := start.Add(-time.Duration(max(i, 1)) * time.Minute)
+		plays[i] = domain.SpotifyRecord{ID: n - i, TS: ts}
+	}
+	return plays
+}
+
+func TestGetPlaysPagesThroughCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		limit     int
+		wantPages []int
+	}{
+		{"última página parcial", 5, 2, []int{2, 2, 1}},
+		{"total múltiplo del límite", 4, 2, []int{2, 2}},
+		{"una sola página exacta", 3, 3, []int{3}},
+		{"menos filas que el límite", 2, 10, []int{2}},
+		{"sin reproducciones", 0, 10, []int{0}},
+		{"límite 1", 3, 1, []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &playsRepo{plays: newPlays(tt.total)}
+			svc := NewSpotifyService(repo, nil)
+
+			var seen []int
+			var page domain.PlayPage
+			for i, want := range tt.wantPages {
+				res, err := svc.GetPlays(context.Background(), domain.SpotifyFilters{Limit: tt.limit}, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(res.Data) != want || res.Limit != tt.limit {
+					t.Fatalf("página %d: %d filas con limit %d, se esperaban %d con limit %d", i+1, len(res.Data), res.Limit, want, tt.limit)
+				}
+				last := i == len(tt.wantPages)-1
+				if res.HasMore == last || (res.NextCursor == "") != last {
+					t.Fatalf("página %d: has_more=%v next_cursor=%q", i+1, res.HasMore, res.NextCursor)
+				}
+				for _, p := range res.Data {
+					seen = append(seen, p.ID)
+				}
+				if last {
+					break
+				}
+
+				// El cursor apunta a la última fila entregada, no a la fila extra usada para has_more
+				cursor, err := domain.DecodePlayCursor(res.NextCursor)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tail := res.Data[len(res.Data)-1]
+				if cursor.ID != tail.ID || !cursor.TS.Equal(tail.TS) {
+					t.Fatalf("página %d: cursor %v, última fila %d %v", i+1, cursor, tail.ID, tail.TS)
+				}
+				page.After = &cursor
+			}
+
+			if len(seen) != tt.total {
+				t.Fatalf("se recorrieron %d reproducciones de %d", len(seen), tt.total)
+			}
+			for i, id := range seen {
+				if id != repo.plays[i].ID {
+					t.Fatalf("recorrido %v: reproducción repetida u omitida en la posición %d", seen, i)
+				}
+			}
+			for _, l := range repo.limits {
+				if l != tt.limit+1 {
+					t.Errorf("el repositorio recibió limit %d, se esperaba %d (una fila extra)", l, tt.limit+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitByDayType(t *testing.T) {
+	tests := []struct {
+		name        string
+		weekdays    []time.Weekday
+		wantWeekday []time.Weekday
+		wantWeekend []time.Weekday
+	}{
+		{"sin weekday=", nil, domain.WorkingDays, domain.WeekendDays},
+		{"weekday=fri,sat", []time.Weekday{time.Friday, time.Saturday}, []time.Weekday{time.Friday}, []time.Weekday{time.Saturday}},
+		{"weekday=mon deja el fin de semana vacío", []time.Weekday{time.Monday}, []time.Weekday{time.Monday}, []time.Weekday{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := SplitByDayType(domain.SpotifyFilters{Weekdays: tt.weekdays}, func(f domain.SpotifyFilters) ([]time.Weekday, error) {
+				return f.Weekdays, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res.Weekday, tt.wantWeekday) || !reflect.DeepEqual(res.Weekend, tt.wantWeekend) {
+				t.Errorf("weekday=%#v weekend=%#v, se esperaba %#v y %#v", res.Weekday, res.Weekend, tt.wantWeekday, tt.wantWeekend)
+			}
+		})
+	}
+}
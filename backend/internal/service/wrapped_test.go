@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/domain"
+	"github.com/IsaacEspinoza91/My-spotify-data/internal/repository"
+)
+
+// wrappedRepo registra las consultas: cualquier método no implementado entra en pánico
+type wrappedRepo struct {
+	repository.SpotifyRepository
+	songFilters []domain.SpotifyFilters
+}
+
+func (r *wrappedRepo) GetTopSongs(ctx context.Context, f domain.SpotifyFilters) ([]domain.SongRankingDTO, int, error) {
+	r.songFilters = append(r.songFilters, f)
+	return nil, 0, nil
+}
+
+func TestGetWrappedTopSongsRunsOneQuery(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Santiago")
+	tests := []struct {
+		name       string
+		month      int
+		season     domain.Season
+		start, end time.Time
+	}{
+		{name: "año", start: time.Date(2024, 1, 1, 0, 0, 0, 0, loc), end: time.Date(2024, 12, 31, 23, 59, 59, 0, loc)},
+		{name: "mes", month: 2, start: time.Date(2024, 2, 1, 0, 0, 0, 0, loc), end: time.Date(2024, 2, 29, 23, 59, 59, 0, loc)},
+		{name: "verano empieza el año anterior", season: domain.Summer, start: time.Date(2023, 12, 21, 0, 0, 0, 0, loc), end: time.Date(2024, 3, 20, 23, 59, 59, 0, loc)},
+		{name: "season tiene prioridad sobre month", month: 2, season: domain.Winter, start: time.Date(2024, 6, 21, 0, 0, 0, 0, loc), end: time.Date(2024, 9, 20, 23, 59, 59, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &wrappedRepo{}
+			svc := NewSpotifyService(repo, nil)
+			songs, err := svc.GetWrappedTopSongs(context.Background(), 2024, tt.month, tt.season, domain.SpotifyFilters{ExcludeIncognito: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if songs == nil {
+				t.Errorf("sin canciones debe responder un arreglo vacío")
+			}
+			if len(repo.songFilters) != 1 {
+				t.Fatalf("%d consultas, se esperaba una", len(repo.songFilters))
+			}
+			f := repo.songFilters[0]
+			if !f.StartDate.Equal(tt.start) || !f.EndDate.Equal(tt.end) {
+				t.Errorf("periodo %v - %v, se esperaba %v - %v", f.StartDate, f.EndDate, tt.start, tt.end)
+			}
+			if f.Limit != wrappedTopLimit || f.Page != 1 || !f.ExcludeIncognito {
+				t.Errorf("filtros %+v", f)
+			}
+		})
+	}
+
+	for _, bad := range []struct {
+		month  int
+		season domain.Season
+	}{{13, ""}, {-1, ""}, {0, "monzón"}} {
+		_, err := NewSpotifyService(&wrappedRepo{}, nil).GetWrappedTopSongs(context.Background(), 2024, bad.month, bad.season, domain.SpotifyFilters{})
+		var appErr *domain.AppError
+		if !errors.As(err, &appErr) || appErr.Kind != domain.ErrKindValidation {
+			t.Errorf("month=%d season=%q: error %v, se esperaba validación", bad.month, bad.season, err)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+-- Contexto de reproducción del export extendido (Streaming_History_Audio_*.json)
+ALTER TABLE spotify_history
+    ADD COLUMN IF NOT EXISTS shuffle BOOLEAN,
+    ADD COLUMN IF NOT EXISTS reason_start TEXT;
+
+-- Índice para el desglose por origen de la reproducción (shuffle, autoplay, click, trackdone)
+CREATE INDEX IF NOT EXISTS idx_spotify_reason_start ON spotify_history (reason_start);
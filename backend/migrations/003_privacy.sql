@@ -0,0 +1,5 @@
+-- Escucha sin conexión y sesiones privadas del export extendido
+ALTER TABLE spotify_history
+    ADD COLUMN IF NOT EXISTS offline BOOLEAN,
+    ADD COLUMN IF NOT EXISTS offline_timestamp TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS incognito_mode BOOLEAN;
@@ -0,0 +1,3 @@
+-- Paginación por cursor del historial de reproducciones (/spotify/plays).
+-- Permite recorrer ORDER BY ts, id en ambos sentidos sin ordenar en memoria
+CREATE INDEX IF NOT EXISTS idx_spotify_ts_id ON spotify_history (ts, id);
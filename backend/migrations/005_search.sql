@@ -0,0 +1,13 @@
+-- Búsqueda aproximada (/spotify/search): similitud por trigramas sin distinguir acentos
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() es STABLE y no puede usarse en índices; este envoltorio fija el diccionario y es IMMUTABLE
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, $1)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Índices GIN sobre el texto normalizado, la consulta debe usar la misma expresión f_unaccent(lower(...))
+CREATE INDEX IF NOT EXISTS idx_spotify_artist_trgm ON spotify_history USING gin (f_unaccent(lower(artist_name)) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_spotify_album_trgm ON spotify_history USING gin (f_unaccent(lower(album_name)) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_spotify_track_trgm ON spotify_history USING gin (f_unaccent(lower(track_name)) gin_trgm_ops);
@@ -0,0 +1,10 @@
+-- Presets de filtros guardados ("vistas"). params usa los mismos nombres que la query:
+-- {"start_date": "2024-01-01", "exclude_artist": ["Ruido blanco"], "start_hour": "8"}
+CREATE TABLE IF NOT EXISTS filter_presets (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    params JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
@@ -0,0 +1,13 @@
+-- Lista de exclusión global: artistas, canciones o URIs que se omiten de todas las
+-- estadísticas salvo con ?include_excluded=true (ruido blanco, música infantil, ...)
+CREATE TABLE IF NOT EXISTS excluded_entities (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('artist', 'track', 'uri')),
+    value TEXT NOT NULL,                  -- Nombre del artista, nombre de la canción o spotify_uri
+    artist_name TEXT NOT NULL DEFAULT '', -- Solo en canciones: acota al artista ('' = cualquiera)
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- La comparación no distingue mayúsculas, el índice único tampoco
+CREATE UNIQUE INDEX IF NOT EXISTS idx_excluded_unique ON excluded_entities (kind, lower(value), lower(artist_name));
@@ -0,0 +1,31 @@
+-- Multiusuario: cada reproducción, preset y exclusión pertenece a un usuario.
+-- Los datos existentes quedan asignados al usuario 'default' (id 1).
+-- Requiere 006_presets.sql y 007_exclusions.sql
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    display_name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO users (id, username, display_name) VALUES (1, 'default', 'Usuario por defecto')
+ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('users', 'id'), (SELECT MAX(id) FROM users));
+
+-- El DEFAULT solo sirve para rellenar las filas existentes; el importador siempre indica el usuario
+ALTER TABLE spotify_history ADD COLUMN IF NOT EXISTS user_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id);
+ALTER TABLE spotify_history ALTER COLUMN user_id DROP DEFAULT;
+-- Todas las consultas filtran por usuario; reemplaza al índice del cursor de /plays
+DROP INDEX IF EXISTS idx_spotify_ts_id;
+CREATE INDEX IF NOT EXISTS idx_spotify_user_ts_id ON spotify_history (user_id, ts, id);
+
+ALTER TABLE filter_presets ADD COLUMN IF NOT EXISTS user_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE filter_presets ALTER COLUMN user_id DROP DEFAULT;
+-- El nombre del preset es único por usuario
+ALTER TABLE filter_presets DROP CONSTRAINT IF EXISTS filter_presets_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_presets_user_name ON filter_presets (user_id, name);
+
+ALTER TABLE excluded_entities ADD COLUMN IF NOT EXISTS user_id INTEGER NOT NULL DEFAULT 1 REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE excluded_entities ALTER COLUMN user_id DROP DEFAULT;
+DROP INDEX IF EXISTS idx_excluded_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_excluded_unique ON excluded_entities (user_id, kind, lower(value), lower(artist_name));
@@ -0,0 +1,15 @@
+-- Enlaces públicos a un wrapped congelado (/share/{token}). Solo se guarda el hash SHA-256 del token:
+-- quien lea la tabla no puede reconstruir los enlaces. Las fechas son TIMESTAMPTZ porque expires_at
+-- se compara con now() y no con el historial en hora local
+-- Requiere 008_users.sql
+CREATE TABLE IF NOT EXISTS share_links (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL UNIQUE,
+    payload JSONB NOT NULL, -- WrappedDTO al momento de compartir
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ,  -- NULL = no expira
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_user ON share_links (user_id, created_at DESC);
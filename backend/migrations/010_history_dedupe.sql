@@ -0,0 +1,13 @@
+-- Reimportar un export no debe duplicar reproducciones: una reproducción se identifica por
+-- usuario, instante de término, URI y duración. Primero se eliminan los duplicados existentes
+-- (se conserva el de menor id). Requiere 008_users.sql
+DELETE FROM spotify_history WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, ts, COALESCE(spotify_uri, ''), ms_played ORDER BY id) AS n
+        FROM spotify_history
+    ) d WHERE n > 1
+);
+
+-- COALESCE: en un índice único los NULL no chocan entre sí y el export trae reproducciones sin URI
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spotify_history_play
+ON spotify_history (user_id, ts, COALESCE(spotify_uri, ''), ms_played);